@@ -2,19 +2,31 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	_ "github.com/felix-kado/vk-test-task/docs"
 	"github.com/felix-kado/vk-test-task/internal/config"
 	handlers "github.com/felix-kado/vk-test-task/internal/handlers"
+	"github.com/felix-kado/vk-test-task/internal/images"
 	"github.com/felix-kado/vk-test-task/internal/logger"
+	"github.com/felix-kado/vk-test-task/internal/middleware"
 	"github.com/felix-kado/vk-test-task/internal/services/ads"
 	"github.com/felix-kado/vk-test-task/internal/services/auth"
+	"github.com/felix-kado/vk-test-task/internal/services/categories"
+	imagesvc "github.com/felix-kado/vk-test-task/internal/services/images"
+	"github.com/felix-kado/vk-test-task/internal/services/rates"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+	"github.com/felix-kado/vk-test-task/internal/storage/memory"
 	"github.com/felix-kado/vk-test-task/internal/storage/postgres"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
@@ -45,16 +57,88 @@ func main() {
 	log.Info("database connection established")
 
 	// 4. Init services
-	authService := auth.New(db, cfg.Auth.JWTSecret, cfg.Auth.TokenTTL)
-	adsService := ads.New(db, db) // db implements both AdRepository and UserRepository
+	revocations, err := newRevocationStore(cfg, db)
+	if err != nil {
+		log.Error("failed to init token revocation store", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	attempts, err := newAttemptStore(cfg, db)
+	if err != nil {
+		log.Error("failed to init login attempt store", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	tokenKeys, err := newTokenKeys(cfg)
+	if err != nil {
+		log.Error("failed to load token signing keys", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	authService := auth.New(db, db, revocations, attempts, tokenKeys, cfg.Auth.TokenTTL, cfg.Auth.RefreshTTL, auth.LockoutPolicy{
+		MaxFailures:   cfg.Login.MaxFailures,
+		IPMaxFailures: cfg.Login.IPMaxFailures,
+		Base:          cfg.Login.LockoutBase,
+		Max:           cfg.Login.LockoutMax,
+		Window:        cfg.Login.FailureWindow,
+	})
+	blobStore, err := newBlobStore(cfg)
+	if err != nil {
+		log.Error("failed to init image store", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	imageFetcher := images.NewStoreFetcher(blobStore, images.NewHTTPFetcher(images.NewHTTPClient(cfg.Images.FetchTimeout)))
+	imagePolicy := ads.ImagePolicy{
+		AllowedSchemes: cfg.Images.AllowedSchemes,
+		AllowedHosts:   cfg.Images.AllowedHosts,
+		MaxSize:        cfg.Images.MaxSize,
+		FetchTimeout:   cfg.Images.FetchTimeout,
+		UploadBaseURL:  blobStore.URL(""),
+	}
+	adsService := ads.New(db, db, db, imageFetcher, imagePolicy, ads.LifecyclePolicy{AdTTL: cfg.Ads.TTL, BumpCooldown: cfg.Ads.BumpCooldown}) // db implements AdRepository, UserRepository and CategoryRepository
+	categoriesService := categories.New(db)
+	ratesService := rates.New(db, db)
+	imagesService := imagesvc.New(blobStore, db, imagesvc.UploadPolicy{
+		MaxSize:   cfg.Images.MaxSize,
+		MaxWidth:  cfg.Images.MaxWidth,
+		MaxHeight: cfg.Images.MaxHeight,
+	})
 
 	// 5. Init transport (router, handlers)
 	authHandler := handlers.NewAuthHandler(authService, log)
 	adsHandler := handlers.NewAdsHandler(adsService, log)
+	categoriesHandler := handlers.NewCategoriesHandler(categoriesService, log)
+	imagesHandler := handlers.NewImagesHandler(imagesService, log)
+	ratesHandler := handlers.NewRatesHandler(ratesService, log)
 
 	// Init router
-	router := handlers.NewRouter(log, authHandler, adsHandler, authService)
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		log.Error("invalid trusted proxies", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	router := handlers.NewRouter(log, authHandler, adsHandler, categoriesHandler, imagesHandler, ratesHandler, authService, trustedProxies)
 	router.Get("/swagger/*", httpSwagger.WrapHandler)
+	if fsStore, ok := blobStore.(*images.FSStore); ok {
+		// Serve local uploads under the path of their public URL
+		publicURL, err := url.Parse(fsStore.URL(""))
+		if err != nil {
+			log.Error("invalid image public URL", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		mediaPath := strings.TrimSuffix(publicURL.Path, "/")
+		router.Handle(mediaPath+"/*", http.StripPrefix(mediaPath, fsStore.Handler()))
+	}
+
+	// Background jobs: resized variants of uploaded images and archiving of expired ads
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	var bgWG sync.WaitGroup
+	variantWorker := imagesvc.NewVariantWorker(blobStore, db, cfg.Images.VariantSizes, cfg.Images.WorkerInterval, log)
+	expiryScheduler := ads.NewExpiryScheduler(db, cfg.Ads.ExpiryInterval, log)
+	for _, run := range []func(context.Context){variantWorker.Run, expiryScheduler.Run} {
+		bgWG.Add(1)
+		go func() {
+			defer bgWG.Done()
+			run(bgCtx)
+		}()
+	}
 
 	// 6. Graceful shutdown
 	done := make(chan os.Signal, 1)
@@ -86,5 +170,67 @@ func main() {
 		log.Error("server shutdown failed", slog.String("error", err.Error()))
 	}
 
+	stopBackground()
+	bgWG.Wait()
+
 	log.Info("server stopped gracefully")
 }
+
+// newTokenKeys loads the keys access tokens are signed and verified with. An asymmetric
+// private key takes precedence; JWT_SECRET alone keeps signing tokens with HS256.
+func newTokenKeys(cfg *config.Config) (*auth.Keys, error) {
+	if cfg.Auth.PrivateKeyFile != "" {
+		return auth.LoadKeys(cfg.Auth.PrivateKeyFile, cfg.Auth.PublicKeyFiles, cfg.Auth.JWTSecret)
+	}
+	if cfg.Auth.JWTSecret == "" {
+		return nil, errors.New("JWT_SECRET or JWT_PRIVATE_KEY_FILE is required")
+	}
+	return auth.NewHMACKeys(cfg.Auth.JWTSecret), nil
+}
+
+// newRevocationStore creates the store for revoked access tokens selected by the config.
+func newRevocationStore(cfg *config.Config, db *postgres.Storage) (storage.RevocationStore, error) {
+	switch cfg.Auth.RevocationStore {
+	case "postgres":
+		return db, nil
+	case "memory":
+		return memory.NewRevocationStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown token revocation store %q", cfg.Auth.RevocationStore)
+	}
+}
+
+// newAttemptStore creates the store for failed login counters selected by the config.
+func newAttemptStore(cfg *config.Config, db *postgres.Storage) (storage.AttemptStore, error) {
+	switch cfg.Login.AttemptStore {
+	case "postgres":
+		return db, nil
+	case "memory":
+		return memory.NewAttemptStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown login attempt store %q", cfg.Login.AttemptStore)
+	}
+}
+
+// newBlobStore creates the store for uploaded images selected by the config.
+func newBlobStore(cfg *config.Config) (images.Store, error) {
+	switch cfg.Images.Store {
+	case "fs":
+		publicURL := cfg.Images.PublicURL
+		if publicURL == "" {
+			publicURL = "http://localhost:8080/media"
+		}
+		return images.NewFSStore(cfg.Images.UploadDir, publicURL)
+	case "s3":
+		return images.NewS3Store(images.S3Config{
+			Endpoint:  cfg.Images.S3.Endpoint,
+			Bucket:    cfg.Images.S3.Bucket,
+			Region:    cfg.Images.S3.Region,
+			AccessKey: cfg.Images.S3.AccessKey,
+			SecretKey: cfg.Images.S3.SecretKey,
+			PublicURL: cfg.Images.PublicURL,
+		}, &http.Client{Timeout: 30 * time.Second}), nil
+	default:
+		return nil, fmt.Errorf("unknown image store %q", cfg.Images.Store)
+	}
+}
@@ -13,6 +13,7 @@ require (
 	github.com/swaggo/http-swagger v1.3.4
 	github.com/swaggo/swag v1.16.5
 	golang.org/x/crypto v0.40.0
+	golang.org/x/image v0.25.0
 )
 
 require golang.org/x/mod v0.26.0 // indirect
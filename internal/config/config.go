@@ -13,14 +13,52 @@ type Config struct {
 	HTTP struct {
 		Addr            string        `env:"HTTP_ADDR" envDefault:":8080"`
 		ShutdownTimeout time.Duration `env:"HTTP_SHUTDOWN_TIMEOUT" envDefault:"5s"`
+		TrustedProxies  []string      `env:"HTTP_TRUSTED_PROXIES" envSeparator:","` // IPs or CIDRs whose X-Forwarded-For and X-Real-IP are believed
 	}
 	DB struct {
 		DSN     string `env:"DB_DSN,required"`
 		MaxOpen int    `env:"DB_MAX_OPEN" envDefault:"10"`
 	}
 	Auth struct {
-		JWTSecret string        `env:"JWT_SECRET,required"`
-		TokenTTL  time.Duration `env:"JWT_TTL" envDefault:"15m"`
+		JWTSecret       string        `env:"JWT_SECRET"`                            // HS256 secret; required unless JWT_PRIVATE_KEY_FILE is set
+		PrivateKeyFile  string        `env:"JWT_PRIVATE_KEY_FILE"`                  // RSA or Ed25519 key in PEM; replaces JWT_SECRET for signing
+		PublicKeyFiles  []string      `env:"JWT_PUBLIC_KEY_FILES" envSeparator:","` // more verification keys in PEM, e.g. the previous one
+		TokenTTL        time.Duration `env:"JWT_TTL" envDefault:"15m"`
+		RefreshTTL      time.Duration `env:"JWT_REFRESH_TTL" envDefault:"720h"`
+		RevocationStore string        `env:"TOKEN_REVOCATION_STORE" envDefault:"postgres"` // "postgres" or "memory"
+	}
+	Login struct {
+		MaxFailures   int           `env:"LOGIN_MAX_FAILURES" envDefault:"5"`    // per login; 0 disables the limit
+		IPMaxFailures int           `env:"LOGIN_IP_MAX_FAILURES" envDefault:"0"` // per IP address; 0 (the default) disables the limit
+		LockoutBase   time.Duration `env:"LOGIN_LOCKOUT_BASE" envDefault:"30s"`
+		LockoutMax    time.Duration `env:"LOGIN_LOCKOUT_MAX" envDefault:"1h"`
+		FailureWindow time.Duration `env:"LOGIN_FAILURE_WINDOW" envDefault:"24h"`
+		AttemptStore  string        `env:"LOGIN_ATTEMPT_STORE" envDefault:"postgres"` // "postgres" or "memory"
+	}
+	Ads struct {
+		TTL            time.Duration `env:"AD_TTL" envDefault:"720h"`
+		ExpiryInterval time.Duration `env:"AD_EXPIRY_INTERVAL" envDefault:"1m"`
+		BumpCooldown   time.Duration `env:"AD_BUMP_COOLDOWN" envDefault:"24h"`
+	}
+	Images struct {
+		AllowedSchemes []string      `env:"IMAGE_ALLOWED_SCHEMES" envSeparator:"," envDefault:"https"`
+		AllowedHosts   []string      `env:"IMAGE_ALLOWED_HOSTS" envSeparator:","`
+		MaxSize        int64         `env:"IMAGE_MAX_SIZE" envDefault:"5242880"`
+		FetchTimeout   time.Duration `env:"IMAGE_FETCH_TIMEOUT" envDefault:"3s"`
+		MaxWidth       int           `env:"IMAGE_MAX_WIDTH" envDefault:"4096"`
+		MaxHeight      int           `env:"IMAGE_MAX_HEIGHT" envDefault:"4096"`
+		Store          string        `env:"IMAGE_STORE" envDefault:"fs"` // "fs" or "s3"
+		UploadDir      string        `env:"IMAGE_UPLOAD_DIR" envDefault:"./uploads"`
+		PublicURL      string        `env:"IMAGE_PUBLIC_URL"` // defaults to http://localhost:8080/media for fs and the bucket URL for s3
+		VariantSizes   []int         `env:"IMAGE_VARIANT_SIZES" envSeparator:"," envDefault:"200,800"`
+		WorkerInterval time.Duration `env:"IMAGE_WORKER_INTERVAL" envDefault:"2s"`
+		S3             struct {
+			Endpoint  string `env:"IMAGE_S3_ENDPOINT"`
+			Bucket    string `env:"IMAGE_S3_BUCKET"`
+			Region    string `env:"IMAGE_S3_REGION" envDefault:"us-east-1"`
+			AccessKey string `env:"IMAGE_S3_ACCESS_KEY"`
+			SecretKey string `env:"IMAGE_S3_SECRET_KEY"`
+		}
 	}
 	LogLevel string `env:"LOG_LEVEL" envDefault:"INFO"`
 }
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// attributeKeyRe restricts attribute names to a safe identifier-like format.
+var attributeKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// ValidAttributeKey reports whether key can be used as an ad attribute name.
+func ValidAttributeKey(key string) bool {
+	return attributeKeyRe.MatchString(key)
+}
+
+// AttributeSchema describes the structured attributes of ads in a category.
+// It is a subset of JSON Schema: an object of scalar properties supporting
+// type, enum, minimum/maximum, minLength/maxLength, required and additionalProperties.
+type AttributeSchema struct {
+	Type                 string                      `json:"type,omitempty"`
+	Properties           map[string]*AttributeSchema `json:"properties,omitempty"`
+	Required             []string                    `json:"required,omitempty"`
+	AdditionalProperties *bool                       `json:"additionalProperties,omitempty"`
+	Enum                 []any                       `json:"enum,omitempty"`
+	Minimum              *float64                    `json:"minimum,omitempty"`
+	Maximum              *float64                    `json:"maximum,omitempty"`
+	MinLength            *int                        `json:"minLength,omitempty"`
+	MaxLength            *int                        `json:"maxLength,omitempty"`
+}
+
+// Validate checks ad attributes against the schema. A nil schema accepts only empty attributes.
+func (s *AttributeSchema) Validate(attrs map[string]any) error {
+	if s == nil {
+		if len(attrs) > 0 {
+			return fmt.Errorf("category does not support attributes")
+		}
+		return nil
+	}
+
+	for _, name := range s.Required {
+		if _, ok := attrs[name]; !ok {
+			return fmt.Errorf("attribute %q is required", name)
+		}
+	}
+
+	// Iterate in a stable order so that error messages are deterministic
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		prop, ok := s.Properties[key]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				return fmt.Errorf("attribute %q is not allowed", key)
+			}
+			continue
+		}
+		if err := prop.validateValue(attrs[key]); err != nil {
+			return fmt.Errorf("attribute %q %v", key, err)
+		}
+	}
+	return nil
+}
+
+// validateValue checks a single scalar attribute value against a property schema.
+func (s *AttributeSchema) validateValue(v any) error {
+	switch s.Type {
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("must be a string")
+		}
+		if s.MinLength != nil && len([]rune(str)) < *s.MinLength {
+			return fmt.Errorf("must be at least %d characters long", *s.MinLength)
+		}
+		if s.MaxLength != nil && len([]rune(str)) > *s.MaxLength {
+			return fmt.Errorf("must be at most %d characters long", *s.MaxLength)
+		}
+	case "integer", "number":
+		n, ok := toFloat(v)
+		if !ok {
+			return fmt.Errorf("must be a number")
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			return fmt.Errorf("must be an integer")
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return fmt.Errorf("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return fmt.Errorf("must be at most %v", *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("must be a boolean")
+		}
+	case "":
+		// Untyped property, only enum applies
+	default:
+		return fmt.Errorf("has unsupported schema type %q", s.Type)
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return scalarEqual(e, v) }) {
+		return fmt.Errorf("must be one of %v", s.Enum)
+	}
+	return nil
+}
+
+// ParseValue converts a raw query string value to the property's type.
+// Untyped properties are inferred as number, boolean or string in that order.
+func (s *AttributeSchema) ParseValue(raw string) (any, error) {
+	typ := ""
+	if s != nil {
+		typ = s.Type
+	}
+
+	switch typ {
+	case "string":
+		return raw, nil
+	case "integer", "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return b, nil
+	}
+
+	if n, err := strconv.ParseFloat(raw, 64); err == nil {
+		return n, nil
+	}
+	if b, err := strconv.ParseBool(raw); err == nil {
+		return b, nil
+	}
+	return raw, nil
+}
+
+// toFloat converts a JSON number to float64.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// scalarEqual compares two JSON scalars, treating all numeric types as equal by value.
+func scalarEqual(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return a == b
+}
+
+// AttributeFilter is a filter on a structured ad attribute.
+// Op is "eq", "min" or "max"; range operators require a numeric Value.
+type AttributeFilter struct {
+	Key   string
+	Op    string
+	Value any
+	Raw   string // value as received, before typing by the service layer
+}
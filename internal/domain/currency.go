@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Currency is an ISO 4217 currency code. Prices are kept in minor units
+// (kopecks, tiyns), which are hundredths in every supported currency.
+type Currency string
+
+// Supported currencies.
+const (
+	CurrencyRUB Currency = "RUB"
+	CurrencyKZT Currency = "KZT"
+	CurrencyBYN Currency = "BYN"
+)
+
+// BaseCurrency is the currency exchange rates are quoted in.
+const BaseCurrency = CurrencyRUB
+
+// Currencies lists every supported currency.
+var Currencies = []Currency{CurrencyRUB, CurrencyKZT, CurrencyBYN}
+
+// Valid reports whether c is a supported currency.
+func (c Currency) Valid() bool {
+	return slices.Contains(Currencies, c)
+}
+
+// ExchangeRate is the price of one unit of a currency in BaseCurrency.
+type ExchangeRate struct {
+	Currency  Currency  `json:"currency"`
+	Rate      float64   `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
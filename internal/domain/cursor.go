@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// AdCursor is the position of the last ad of a page in keyset pagination.
+// It holds the sort key of that ad together with its ID as a tie-breaker.
+type AdCursor struct {
+	SortBy   string    `json:"s"`
+	Order    string    `json:"o"`
+	Price    int64     `json:"p,omitempty"`
+	NoPrice  bool      `json:"np,omitempty"` // the price could not be converted to Currency
+	Currency Currency  `json:"c,omitempty"`  // currency of Price when prices are converted
+	BumpedAt time.Time `json:"t,omitempty"`
+	ID       int64     `json:"id"`
+}
+
+// NewAdCursor builds a cursor pointing right after the given ad for the given sorting.
+// Prices are taken as converted for display, which is what the feed is sorted by.
+func NewAdCursor(ad *Ad, sortBy, order string) *AdCursor {
+	c := &AdCursor{SortBy: sortBy, Order: order, ID: ad.ID}
+	switch sortBy {
+	case "price":
+		switch {
+		case ad.DisplayPrice != nil:
+			c.Price, c.Currency = *ad.DisplayPrice, ad.DisplayCurrency
+		case ad.DisplayCurrency != "":
+			c.NoPrice, c.Currency = true, ad.DisplayCurrency
+		default:
+			c.Price = ad.Price
+		}
+	default:
+		c.BumpedAt = ad.BumpedAt
+	}
+	return c
+}
+
+// SortKey returns the value of the sort column stored in the cursor, or nil
+// if the ad had none.
+func (c *AdCursor) SortKey() any {
+	if c.SortBy == "price" {
+		if c.NoPrice {
+			return nil
+		}
+		return c.Price
+	}
+	return c.BumpedAt
+}
+
+// Encode returns the opaque string representation of the cursor.
+func (c *AdCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeAdCursor parses a cursor previously produced by Encode.
+func DecodeAdCursor(s string) (*AdCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c AdCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+// FacetCount is the number of ads sharing a facet value.
+type FacetCount struct {
+	ID    int64  // category ID, zero for facets keyed by label only
+	Label string // category name or author login
+	Count int64
+}
+
+// PriceBucket is the number of ads with Min <= price < Max. Max is nil for the last, open-ended bucket.
+type PriceBucket struct {
+	Min   int64
+	Max   *int64
+	Count int64
+}
+
+// AdFacets holds aggregate counts over the ad feed for the filter sidebar.
+// Only non-empty values are included.
+type AdFacets struct {
+	Categories   []FacetCount
+	PriceBuckets []PriceBucket
+	Authors      []FacetCount
+}
+
+// DefaultPriceBuckets are the histogram bounds in cents used when the client does not provide any.
+var DefaultPriceBuckets = []int64{0, 100000, 500000, 1000000, 5000000, 10000000}
+
+// NewPriceBuckets turns ascending bounds and per-bucket counts into price buckets.
+// counts maps a 1-based bucket index (as returned by Postgres width_bucket) to its count;
+// index 0 holds prices below the first bound, which become a bucket starting at zero.
+func NewPriceBuckets(bounds []int64, counts map[int]int64) []PriceBucket {
+	buckets := make([]PriceBucket, 0, len(bounds)+1)
+	if count := counts[0]; count > 0 && len(bounds) > 0 {
+		upper := bounds[0]
+		buckets = append(buckets, PriceBucket{Min: 0, Max: &upper, Count: count})
+	}
+	for i := range bounds {
+		count := counts[i+1]
+		if count == 0 {
+			continue
+		}
+		bucket := PriceBucket{Min: bounds[i], Count: count}
+		if i+1 < len(bounds) {
+			upper := bounds[i+1]
+			bucket.Max = &upper
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
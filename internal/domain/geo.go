@@ -0,0 +1,20 @@
+package domain
+
+import "errors"
+
+// GeoPoint is a location given in decimal degrees.
+type GeoPoint struct {
+	Lat float64
+	Lon float64
+}
+
+// Validate checks that the point lies within the valid latitude and longitude ranges.
+func (p GeoPoint) Validate() error {
+	if p.Lat < -90 || p.Lat > 90 {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if p.Lon < -180 || p.Lon > 180 {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	return nil
+}
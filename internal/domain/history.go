@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// AdVersion is the state of an ad before one of its edits. Together with the
+// current ad, the versions show what the ad looked like at any point in time.
+type AdVersion struct {
+	ID          int64     `json:"id"`
+	AdID        int64     `json:"ad_id"`
+	Title       string    `json:"title"`
+	Text        string    `json:"text"`
+	Price       int64     `json:"price"`
+	Currency    Currency  `json:"currency"`
+	ImageURL    string    `json:"image_url,omitempty"`
+	EditorID    *int64    `json:"editor_id,omitempty"` // user who made the edit; nil if the account was deleted
+	EditorLogin string    `json:"editor_login,omitempty"`
+	EditedAt    time.Time `json:"edited_at"` // when this version was replaced
+}
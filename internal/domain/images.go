@@ -0,0 +1,50 @@
+package domain
+
+// Supported image content types.
+const (
+	ImageTypeJPEG = "image/jpeg"
+	ImageTypePNG  = "image/png"
+	ImageTypeWebP = "image/webp"
+)
+
+// ImageInfo describes a remote image as reported by the server hosting it.
+type ImageInfo struct {
+	ContentType string // media type without parameters
+	Size        int64  // size in bytes, or -1 if the server did not report it
+}
+
+// IsSupportedImageType reports whether images of the content type are accepted.
+func IsSupportedImageType(contentType string) bool {
+	switch contentType {
+	case ImageTypeJPEG, ImageTypePNG, ImageTypeWebP:
+		return true
+	}
+	return false
+}
+
+// UploadedImage is an image stored by the marketplace.
+type UploadedImage struct {
+	ID          int64
+	UserID      int64
+	Key         string
+	URL         string
+	ContentType string
+	Size        int64
+	Width       int
+	Height      int
+}
+
+// ImageVariant is a resized copy of an uploaded image.
+type ImageVariant struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// AdImage is one of the ordered images of an ad.
+type AdImage struct {
+	ID       int64          `json:"id"`
+	URL      string         `json:"url"`
+	Position int            `json:"position"`
+	Variants []ImageVariant `json:"variants,omitempty"` // set only for images uploaded through the API
+}
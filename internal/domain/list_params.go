@@ -3,21 +3,53 @@ package domain
 // ListAdsParams contains parameters for listing ads with pagination and filtering.
 type ListAdsParams struct {
 	// Sorting
-	SortBy string // "price" or "created_at"
+	SortBy string // "price", "created_at" (by bump time), "relevance" (requires Query) or "distance" (requires Near)
 	Order  string // "asc" or "desc"
-	
+
 	// Pagination
 	Page  int // 1-based page number
 	Limit int // number of items per page
-	
+
+	// Keyset pagination
+	CursorMode bool      // keyset pagination; ListAds then returns one extra ad if there is a next page
+	Cursor     string    // opaque cursor from a previous page, empty for the first one (optional)
+	After      *AdCursor // decoded Cursor, set by the service layer
+
 	// Filtering
 	MinPrice *int64 // minimum price filter (optional)
 	MaxPrice *int64 // maximum price filter (optional)
+	Query    string // full-text search over title and text (optional)
+
+	PriceDropped bool // only ads whose latest price change is a drop (optional)
+
+	Currency Currency // prices are converted to this currency, BaseCurrency by default, and MinPrice and MaxPrice are in it (optional)
+
+	CategoryID *int64            // category filter including its descendants (optional)
+	Attributes []AttributeFilter // structured attribute filters (optional)
+
+	Near     *GeoPoint // origin for distances and the radius filter (optional)
+	RadiusKm *float64  // maximum distance from Near in kilometres (optional)
+
+	OwnerID  *int64     // only ads of this user, who may see all of their statuses (optional)
+	Statuses []AdStatus // status filter; see VisibleStatuses for the default (optional)
+}
+
+// VisibleStatuses returns the statuses of the ads to list: Statuses if set,
+// otherwise every status for an owner's own ads and published ads for everyone else.
+func (p *ListAdsParams) VisibleStatuses() []AdStatus {
+	if len(p.Statuses) > 0 {
+		return p.Statuses
+	}
+	if p.OwnerID != nil {
+		return AdStatuses
+	}
+	return []AdStatus{AdStatusPublished}
 }
 
 // GetOffset calculates the SQL OFFSET value from page and limit.
+// Keyset pagination does not use an offset.
 func (p *ListAdsParams) GetOffset() int {
-	if p.Page <= 1 {
+	if p.After != nil || p.Page <= 1 {
 		return 0
 	}
 	return (p.Page - 1) * p.Limit
@@ -29,7 +61,12 @@ func (p *ListAdsParams) SetDefaults() {
 		p.SortBy = "created_at"
 	}
 	if p.Order == "" {
-		p.Order = "desc"
+		// Nearest first when sorting by distance
+		if p.SortBy == "distance" {
+			p.Order = "asc"
+		} else {
+			p.Order = "desc"
+		}
 	}
 	if p.Page <= 0 {
 		p.Page = 1
@@ -37,4 +74,19 @@ func (p *ListAdsParams) SetDefaults() {
 	if p.Limit <= 0 {
 		p.Limit = 10 // default page size
 	}
+	if p.Currency == "" {
+		// Prices in different currencies are only comparable once converted
+		p.Currency = BaseCurrency
+	}
+}
+
+// AdPage is a page of ads together with the total number of ads matching the filters.
+type AdPage struct {
+	Ads   []Ad
+	Total int64
+}
+
+// HasNext reports whether there are more ads after this page in page/limit pagination.
+func (p *ListAdsParams) HasNext(total int64) bool {
+	return int64(p.GetOffset()+p.Limit) < total
 }
@@ -1,21 +1,147 @@
 package domain
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 type User struct {
 	ID           int64     `json:"id"`
 	Login        string    `json:"login"`
 	PasswordHash string    `json:"-"`
+	IsAdmin      bool      `json:"is_admin"`
+	TokenVersion int       `json:"-"` // access tokens with another version are rejected
 	CreatedAt    time.Time `json:"created_at"`
 }
 
 type Ad struct {
-	ID          int64     `json:"id"`
-	UserID      int64     `json:"user_id"`
-	Title       string    `json:"title"`
-	Text        string    `json:"text"`
-	ImageURL    string    `json:"image_url,omitempty"`
-	Price       int64     `json:"price"`
-	AuthorLogin string    `json:"author_login"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID              int64          `json:"id"`
+	UserID          int64          `json:"user_id"`
+	Title           string         `json:"title"`
+	Text            string         `json:"text"`
+	ImageURL        string         `json:"image_url,omitempty"`      // URL of the cover, the first of Images
+	ImageVariants   []ImageVariant `json:"image_variants,omitempty"` // resized copies of the cover, smallest first
+	Images          []AdImage      `json:"images,omitempty"`
+	Price           int64          `json:"price"`
+	Currency        Currency       `json:"currency"`
+	DisplayPrice    *int64         `json:"display_price,omitempty"`    // Price converted to DisplayCurrency, set when listing in a currency
+	DisplayCurrency Currency       `json:"display_currency,omitempty"` // currency requested by the viewer
+	PreviousPrice   *int64         `json:"previous_price,omitempty"`   // price before the latest price change
+	PriceDroppedAt  *time.Time     `json:"price_dropped_at,omitempty"` // set while the latest price change is a drop
+	CategoryID      int64          `json:"category_id"`
+	Attributes      map[string]any `json:"attributes,omitempty"`
+	Latitude        *float64       `json:"latitude,omitempty"`
+	Longitude       *float64       `json:"longitude,omitempty"`
+	City            string         `json:"city,omitempty"`
+	DistanceKm      *float64       `json:"distance_km,omitempty"` // set only when listing near a location
+	AuthorLogin     string         `json:"author_login"`
+	Status          AdStatus       `json:"status"`
+	StatusChangedAt time.Time      `json:"status_changed_at"`
+	ExpiresAt       time.Time      `json:"expires_at"` // published and reserved ads are archived after this time
+	BumpedAt        time.Time      `json:"bumped_at"`  // when the ad was posted or last bumped; orders the feed
+	CreatedAt       time.Time      `json:"created_at"`
+}
+
+// Location returns the ad location, or nil if the ad has none.
+func (a *Ad) Location() *GeoPoint {
+	if a.Latitude == nil || a.Longitude == nil {
+		return nil
+	}
+	return &GeoPoint{Lat: *a.Latitude, Lon: *a.Longitude}
+}
+
+// ImageURLs returns the URLs of the ad images in order.
+func (a *Ad) ImageURLs() []string {
+	urls := make([]string, len(a.Images))
+	for i, img := range a.Images {
+		urls[i] = img.URL
+	}
+	return urls
+}
+
+// SetImageURLs replaces the images of the ad with urls, in order, and makes the
+// first one the cover. Images that are kept retain their ID and variants.
+func (a *Ad) SetImageURLs(urls []string) {
+	images := make([]AdImage, len(urls))
+	for i, u := range urls {
+		images[i] = AdImage{URL: u, Position: i}
+		for _, old := range a.Images {
+			if old.URL == u {
+				images[i].ID, images[i].Variants = old.ID, old.Variants
+				break
+			}
+		}
+	}
+	a.Images = images
+
+	a.ImageURL, a.ImageVariants = "", nil
+	if len(images) > 0 {
+		a.ImageURL, a.ImageVariants = images[0].URL, images[0].Variants
+	}
+}
+
+// AdPatch holds a partial update of an ad. Nil fields are left unchanged.
+type AdPatch struct {
+	Title      *string
+	Text       *string
+	ImageURL   *string  // replaces the cover, or removes it when empty
+	Images     []string // replaces all images when non-nil; takes precedence over ImageURL
+	Price      *int64
+	Currency   *Currency
+	CategoryID *int64
+	Attributes map[string]any // replaces all attributes when non-nil
+	Location   *GeoPoint      // replaces the location when non-nil
+	City       *string
+}
+
+// Apply copies the set fields of the patch onto the ad.
+func (p *AdPatch) Apply(ad *Ad) {
+	if p.Title != nil {
+		ad.Title = *p.Title
+	}
+	if p.Text != nil {
+		ad.Text = *p.Text
+	}
+	switch {
+	case p.Images != nil:
+		ad.SetImageURLs(p.Images)
+	case p.ImageURL != nil:
+		// The new cover replaces the old one; an image already in the list moves to the front
+		urls := ad.ImageURLs()
+		if len(urls) > 0 {
+			urls = urls[1:]
+		}
+		if *p.ImageURL != "" {
+			urls = append([]string{*p.ImageURL}, slices.DeleteFunc(urls, func(u string) bool { return u == *p.ImageURL })...)
+		}
+		ad.SetImageURLs(urls)
+	}
+	if p.Price != nil {
+		ad.Price = *p.Price
+	}
+	if p.Currency != nil {
+		ad.Currency = *p.Currency
+	}
+	if p.CategoryID != nil {
+		ad.CategoryID = *p.CategoryID
+	}
+	if p.Attributes != nil {
+		ad.Attributes = p.Attributes
+	}
+	if p.Location != nil {
+		ad.Latitude, ad.Longitude = &p.Location.Lat, &p.Location.Lon
+	}
+	if p.City != nil {
+		ad.City = *p.City
+	}
+}
+
+// Category is a node of the ad category tree. ParentID is nil for top-level categories.
+type Category struct {
+	ID              int64            `json:"id"`
+	ParentID        *int64           `json:"parent_id"`
+	Name            string           `json:"name"`
+	Slug            string           `json:"slug"`
+	AttributeSchema *AttributeSchema `json:"attribute_schema,omitempty"`
+	Children        []*Category      `json:"children,omitempty" db:"-"`
 }
@@ -0,0 +1,36 @@
+package domain
+
+// AdStatus is a stage of the ad lifecycle.
+type AdStatus string
+
+// Ad lifecycle statuses.
+const (
+	AdStatusDraft     AdStatus = "draft"     // visible to the owner only
+	AdStatusPublished AdStatus = "published" // listed in the feed
+	AdStatusReserved  AdStatus = "reserved"  // a buyer is found, but the deal is not closed
+	AdStatusSold      AdStatus = "sold"
+	AdStatusArchived  AdStatus = "archived" // withdrawn by the owner, visible to the owner only
+)
+
+// AdStatuses lists every ad status.
+var AdStatuses = []AdStatus{AdStatusDraft, AdStatusPublished, AdStatusReserved, AdStatusSold, AdStatusArchived}
+
+// Valid reports whether s is a known status.
+func (s AdStatus) Valid() bool {
+	switch s {
+	case AdStatusDraft, AdStatusPublished, AdStatusReserved, AdStatusSold, AdStatusArchived:
+		return true
+	}
+	return false
+}
+
+// Listed reports whether ads with the status may be listed to everyone.
+func (s AdStatus) Listed() bool {
+	return s == AdStatusPublished || s == AdStatusReserved
+}
+
+// Public reports whether ads with the status may be viewed by anyone with a link.
+// Sold ads stay viewable so that old links explain where the item went.
+func (s AdStatus) Public() bool {
+	return s.Listed() || s == AdStatusSold
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"crypto"
+	"time"
+)
+
+// TokenPair is what a user gets on login: a short-lived access token for API
+// requests and a long-lived refresh token to get the next pair.
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// RefreshToken is a stored refresh token. Only the hash of the token is kept.
+// Tokens issued one from another since a login share a family, the ID of the session.
+type RefreshToken struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	FamilyID  string     `json:"family_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`    // set when the token was exchanged for the next one
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // set when the family was revoked
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Session is one login of a user on one device.
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     int64      `json:"user_id"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"` // updated at most once a minute
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ClientInfo describes the client a request comes from.
+type ClientInfo struct {
+	UserAgent string
+	IP        string // the client's address, behind trusted proxies as well
+}
+
+// PublicKey is a key other services verify access tokens with.
+type PublicKey struct {
+	ID        string           // kid of tokens signed with the key
+	Algorithm string           // JWS algorithm, e.g. RS256 or EdDSA
+	Key       crypto.PublicKey // *rsa.PublicKey or ed25519.PublicKey
+}
+
+// Attempts are the consecutive failed attempts made with a key, e.g. a login or an IP address.
+type Attempts struct {
+	Failures     int       `json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+}
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"math"
 	"time"
 
 	"github.com/felix-kado/vk-test-task/internal/domain"
@@ -8,31 +9,80 @@ import (
 
 // AdResponse is a DTO for the Ad model, including an ownership flag and author login.
 type AdResponse struct {
-	ID          int64     `json:"id"`
-	UserID      int64     `json:"user_id"`
-	Title       string    `json:"title"`
-	Text        string    `json:"text"`
-	ImageURL    string    `json:"image_url"`
-	Price       int64     `json:"price"`
-	CreatedAt   time.Time `json:"created_at"`
-	AuthorLogin string    `json:"author_login"`
-	IsOwner     bool      `json:"is_owner"`
+	ID              int64                  `json:"id"`
+	UserID          int64                  `json:"user_id"`
+	Title           string                 `json:"title"`
+	Text            string                 `json:"text"`
+	ImageURL        string                 `json:"image_url"`
+	ImageVariants   []ImageVariantResponse `json:"image_variants,omitempty"` // empty for external images and until processing finishes
+	Images          []AdImageResponse      `json:"images,omitempty"`
+	Cover           *AdImageResponse       `json:"cover,omitempty"`
+	Price           int64                  `json:"price"`
+	Currency        string                 `json:"currency"`
+	DisplayPrice    *int64                 `json:"display_price,omitempty"` // price converted to display_currency
+	DisplayCurrency string                 `json:"display_currency,omitempty"`
+	PreviousPrice   *int64                 `json:"previous_price,omitempty"`
+	PriceDroppedAt  *time.Time             `json:"price_dropped_at,omitempty"`
+	CategoryID      int64                  `json:"category_id"`
+	Attributes      map[string]any         `json:"attributes,omitempty"`
+	Latitude        *float64               `json:"latitude,omitempty"`
+	Longitude       *float64               `json:"longitude,omitempty"`
+	City            string                 `json:"city,omitempty"`
+	DistanceKm      *float64               `json:"distance_km,omitempty"`
+	CreatedAt       time.Time              `json:"created_at"`
+	Status          string                 `json:"status"`
+	ExpiresAt       time.Time              `json:"expires_at"`
+	BumpedAt        time.Time              `json:"bumped_at"`
+	AuthorLogin     string                 `json:"author_login"`
+	IsOwner         bool                   `json:"is_owner"`
 }
 
 // ToAdResponse converts a domain.Ad to AdResponse DTO.
 // currentUserID is used to determine ownership (0 for unauthenticated users).
 func ToAdResponse(ad *domain.Ad, currentUserID int64) *AdResponse {
+	images := ToAdImageResponseList(ad.Images)
+	var cover *AdImageResponse
+	if len(images) > 0 {
+		cover = &images[0]
+	}
+
 	return &AdResponse{
-		ID:          ad.ID,
-		UserID:      ad.UserID,
-		Title:       ad.Title,
-		Text:        ad.Text,
-		ImageURL:    ad.ImageURL,
-		Price:       ad.Price,
-		CreatedAt:   ad.CreatedAt,
-		AuthorLogin: ad.AuthorLogin,
-		IsOwner:     currentUserID != 0 && currentUserID == ad.UserID,
+		ID:              ad.ID,
+		UserID:          ad.UserID,
+		Title:           ad.Title,
+		Text:            ad.Text,
+		ImageURL:        ad.ImageURL,
+		ImageVariants:   ToImageVariantResponseList(ad.ImageVariants),
+		Images:          images,
+		Cover:           cover,
+		Price:           ad.Price,
+		Currency:        string(ad.Currency),
+		DisplayPrice:    ad.DisplayPrice,
+		DisplayCurrency: string(ad.DisplayCurrency),
+		PreviousPrice:   ad.PreviousPrice,
+		PriceDroppedAt:  ad.PriceDroppedAt,
+		CategoryID:      ad.CategoryID,
+		Attributes:      ad.Attributes,
+		Latitude:        ad.Latitude,
+		Longitude:       ad.Longitude,
+		City:            ad.City,
+		DistanceKm:      roundDistance(ad.DistanceKm),
+		CreatedAt:       ad.CreatedAt,
+		Status:          string(ad.Status),
+		ExpiresAt:       ad.ExpiresAt,
+		BumpedAt:        ad.BumpedAt,
+		AuthorLogin:     ad.AuthorLogin,
+		IsOwner:         currentUserID != 0 && currentUserID == ad.UserID,
+	}
+}
+
+// roundDistance rounds a distance to 10 metres, which is plenty for local pickup.
+func roundDistance(km *float64) *float64 {
+	if km == nil {
+		return nil
 	}
+	rounded := math.Round(*km*100) / 100
+	return &rounded
 }
 
 // ToAdResponseList converts a slice of domain.Ad to AdResponse DTOs.
@@ -44,3 +94,58 @@ func ToAdResponseList(ads []domain.Ad, currentUserID int64) []*AdResponse {
 	}
 	return responses
 }
+
+// AdCursorPage is a page of ads returned in keyset pagination mode.
+// NextCursor is empty when there are no more ads.
+type AdCursorPage struct {
+	Items      []*AdResponse `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// ToAdCursorPage converts a page of ads to AdCursorPage DTO.
+// ads may hold one ad more than the limit, which is not returned but tells that
+// there is a next page; only then a next cursor is produced.
+func ToAdCursorPage(ads []domain.Ad, params *domain.ListAdsParams, currentUserID int64) *AdCursorPage {
+	var next string
+	if len(ads) > params.Limit {
+		ads = ads[:params.Limit]
+		next = domain.NewAdCursor(&ads[len(ads)-1], params.SortBy, params.Order).Encode()
+	}
+	return &AdCursorPage{Items: ToAdResponseList(ads, currentUserID), NextCursor: next}
+}
+
+// AdListResponse is the paginated envelope for the ad feed.
+// Page is omitted in keyset pagination mode, where NextCursor is used instead.
+type AdListResponse struct {
+	Items      []*AdResponse `json:"items"`
+	Page       int           `json:"page,omitempty"`
+	Limit      int           `json:"limit"`
+	Total      int64         `json:"total"`
+	HasNext    bool          `json:"has_next"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// ToAdListResponse converts a page of ads to the AdListResponse envelope.
+// currentUserID is used to determine ownership (0 for unauthenticated users).
+func ToAdListResponse(page *domain.AdPage, params *domain.ListAdsParams, currentUserID int64) *AdListResponse {
+	resp := &AdListResponse{
+		Limit: params.Limit,
+		Total: page.Total,
+	}
+	if params.CursorMode {
+		cursorPage := ToAdCursorPage(page.Ads, params, currentUserID)
+		resp.Items = cursorPage.Items
+		resp.NextCursor = cursorPage.NextCursor
+		resp.HasNext = cursorPage.NextCursor != ""
+	} else {
+		resp.Items = ToAdResponseList(page.Ads, currentUserID)
+		resp.Page = params.Page
+		resp.HasNext = params.HasNext(page.Total)
+	}
+	return resp
+}
+
+// SuggestResponse is a list of ad titles for search autocomplete.
+type SuggestResponse struct {
+	Suggestions []string `json:"suggestions"`
+}
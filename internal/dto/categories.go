@@ -0,0 +1,27 @@
+package dto
+
+import "github.com/felix-kado/vk-test-task/internal/domain"
+
+// CategoryResponse is a DTO for a node of the category tree.
+type CategoryResponse struct {
+	ID              int64                   `json:"id"`
+	Name            string                  `json:"name"`
+	Slug            string                  `json:"slug"`
+	AttributeSchema *domain.AttributeSchema `json:"attribute_schema,omitempty"`
+	Children        []*CategoryResponse     `json:"children,omitempty"`
+}
+
+// ToCategoryTree converts a category tree to CategoryResponse DTOs.
+func ToCategoryTree(categories []*domain.Category) []*CategoryResponse {
+	responses := make([]*CategoryResponse, len(categories))
+	for i, c := range categories {
+		responses[i] = &CategoryResponse{
+			ID:              c.ID,
+			Name:            c.Name,
+			Slug:            c.Slug,
+			AttributeSchema: c.AttributeSchema,
+			Children:        ToCategoryTree(c.Children),
+		}
+	}
+	return responses
+}
@@ -0,0 +1,49 @@
+package dto
+
+import "github.com/felix-kado/vk-test-task/internal/domain"
+
+// CategoryFacet is the number of ads in a category.
+type CategoryFacet struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// PriceBucketFacet is the number of ads with min <= price < max; max is omitted for the last bucket.
+type PriceBucketFacet struct {
+	Min   int64  `json:"min"`
+	Max   *int64 `json:"max,omitempty"`
+	Count int64  `json:"count"`
+}
+
+// AuthorFacet is the number of ads posted by an author.
+type AuthorFacet struct {
+	Login string `json:"login"`
+	Count int64  `json:"count"`
+}
+
+// AdFacetsResponse is a DTO with aggregate counts for the feed filter sidebar.
+type AdFacetsResponse struct {
+	Categories   []CategoryFacet    `json:"categories"`
+	PriceBuckets []PriceBucketFacet `json:"price_buckets"`
+	Authors      []AuthorFacet      `json:"authors"`
+}
+
+// ToAdFacetsResponse converts domain.AdFacets to AdFacetsResponse DTO.
+func ToAdFacetsResponse(facets *domain.AdFacets) *AdFacetsResponse {
+	resp := &AdFacetsResponse{
+		Categories:   make([]CategoryFacet, len(facets.Categories)),
+		PriceBuckets: make([]PriceBucketFacet, len(facets.PriceBuckets)),
+		Authors:      make([]AuthorFacet, len(facets.Authors)),
+	}
+	for i, c := range facets.Categories {
+		resp.Categories[i] = CategoryFacet{ID: c.ID, Name: c.Label, Count: c.Count}
+	}
+	for i, b := range facets.PriceBuckets {
+		resp.PriceBuckets[i] = PriceBucketFacet{Min: b.Min, Max: b.Max, Count: b.Count}
+	}
+	for i, a := range facets.Authors {
+		resp.Authors[i] = AuthorFacet{Login: a.Label, Count: a.Count}
+	}
+	return resp
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// AdVersionResponse is a DTO for the state of an ad before one of its edits.
+type AdVersionResponse struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Text        string    `json:"text"`
+	Price       int64     `json:"price"`
+	Currency    string    `json:"currency"`
+	ImageURL    string    `json:"image_url,omitempty"`
+	EditorID    *int64    `json:"editor_id,omitempty"`
+	EditorLogin string    `json:"editor_login,omitempty"`
+	EditedAt    time.Time `json:"edited_at"`
+}
+
+// ToAdVersionResponseList converts ad versions to AdVersionResponse DTOs.
+func ToAdVersionResponseList(versions []domain.AdVersion) []AdVersionResponse {
+	responses := make([]AdVersionResponse, len(versions))
+	for i, v := range versions {
+		responses[i] = AdVersionResponse{
+			ID:          v.ID,
+			Title:       v.Title,
+			Text:        v.Text,
+			Price:       v.Price,
+			Currency:    string(v.Currency),
+			ImageURL:    v.ImageURL,
+			EditorID:    v.EditorID,
+			EditorLogin: v.EditorLogin,
+			EditedAt:    v.EditedAt,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,61 @@
+package dto
+
+import "github.com/felix-kado/vk-test-task/internal/domain"
+
+// ImageResponse is a DTO for an uploaded image. URL can be used as the image_url of an ad.
+type ImageResponse struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+// ToImageResponse converts domain.UploadedImage to ImageResponse DTO.
+func ToImageResponse(img *domain.UploadedImage) *ImageResponse {
+	return &ImageResponse{
+		URL:         img.URL,
+		ContentType: img.ContentType,
+		Size:        img.Size,
+		Width:       img.Width,
+		Height:      img.Height,
+	}
+}
+
+// ImageVariantResponse is a DTO for a resized copy of an ad image.
+type ImageVariantResponse struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ToImageVariantResponseList converts image variants to ImageVariantResponse DTOs.
+func ToImageVariantResponseList(variants []domain.ImageVariant) []ImageVariantResponse {
+	if len(variants) == 0 {
+		return nil
+	}
+	responses := make([]ImageVariantResponse, len(variants))
+	for i, v := range variants {
+		responses[i] = ImageVariantResponse{URL: v.URL, Width: v.Width, Height: v.Height}
+	}
+	return responses
+}
+
+// AdImageResponse is a DTO for one of the images of an ad.
+type AdImageResponse struct {
+	ID       int64                  `json:"id"`
+	URL      string                 `json:"url"`
+	Variants []ImageVariantResponse `json:"variants,omitempty"`
+}
+
+// ToAdImageResponseList converts the images of an ad to AdImageResponse DTOs, in order.
+func ToAdImageResponseList(images []domain.AdImage) []AdImageResponse {
+	if len(images) == 0 {
+		return nil
+	}
+	responses := make([]AdImageResponse, len(images))
+	for i, img := range images {
+		responses[i] = AdImageResponse{ID: img.ID, URL: img.URL, Variants: ToImageVariantResponseList(img.Variants)}
+	}
+	return responses
+}
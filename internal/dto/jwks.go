@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// JWK is a public key in the JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // curve of an OKP key
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKSResponse is a DTO for a JSON Web Key Set.
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ToJWKSResponse converts public keys to a JWKSResponse DTO, skipping keys of unknown types.
+func ToJWKSResponse(keys []domain.PublicKey) *JWKSResponse {
+	resp := &JWKSResponse{Keys: make([]JWK, 0, len(keys))}
+	for _, k := range keys {
+		jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Algorithm}
+		switch pub := k.Key.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		resp.Keys = append(resp.Keys, jwk)
+	}
+	return resp
+}
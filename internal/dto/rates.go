@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// ExchangeRateResponse is a DTO for the exchange rate of a currency.
+type ExchangeRateResponse struct {
+	Currency  string    `json:"currency"`
+	Rate      float64   `json:"rate"` // units of the base currency per unit of currency
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ToExchangeRateResponseList converts exchange rates to ExchangeRateResponse DTOs.
+func ToExchangeRateResponseList(rates []domain.ExchangeRate) []ExchangeRateResponse {
+	responses := make([]ExchangeRateResponse, len(rates))
+	for i, r := range rates {
+		responses[i] = ExchangeRateResponse{Currency: string(r.Currency), Rate: r.Rate, UpdatedAt: r.UpdatedAt}
+	}
+	return responses
+}
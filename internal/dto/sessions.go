@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// SessionResponse is a DTO for an active session of the user.
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// ToSessionResponseList converts sessions to SessionResponse DTOs.
+func ToSessionResponseList(sessions []domain.Session) []SessionResponse {
+	responses := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		responses[i] = SessionResponse{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+		}
+	}
+	return responses
+}
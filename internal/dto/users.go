@@ -23,3 +23,29 @@ func ToUserResponse(user *domain.User) *UserResponse {
 		CreatedAt: user.CreatedAt,
 	}
 }
+
+// TokenResponse is a DTO for a token pair, used in login and refresh responses.
+type TokenResponse struct {
+	Token            string    `json:"token"` // same as AccessToken, kept for older clients
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// ToTokenResponse converts a domain.TokenPair to TokenResponse DTO.
+func ToTokenResponse(tokens *domain.TokenPair) *TokenResponse {
+	return &TokenResponse{
+		Token:            tokens.AccessToken,
+		AccessToken:      tokens.AccessToken,
+		AccessExpiresAt:  tokens.AccessExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	}
+}
+
+// RegistrationResponse is a DTO for the registered user together with their first token pair.
+type RegistrationResponse struct {
+	*UserResponse
+	*TokenResponse
+}
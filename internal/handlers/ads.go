@@ -6,17 +6,36 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/felix-kado/vk-test-task/internal/domain"
 	"github.com/felix-kado/vk-test-task/internal/dto"
 	"github.com/felix-kado/vk-test-task/internal/middleware"
+	"github.com/go-chi/chi/v5"
 )
 
+// attributeFilterPrefix marks query parameters that filter by structured attributes.
+const attributeFilterPrefix = "attr."
+
 // AdsService defines the interface for ad-related operations.
 type AdsService interface {
 	CreateAd(ctx context.Context, ad *domain.Ad) (int64, error)
+	GetVisibleAd(ctx context.Context, viewerID, adID int64) (*domain.Ad, error)
+	UpdateAd(ctx context.Context, userID, adID int64, patch *domain.AdPatch) (*domain.Ad, error)
+	DeleteAd(ctx context.Context, userID, adID int64) error
 	ListAds(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error)
+	ListAdsPage(ctx context.Context, params *domain.ListAdsParams) (*domain.AdPage, error)
+	SuggestTitles(ctx context.Context, prefix string, limit int) ([]string, error)
+	GetFacets(ctx context.Context, params *domain.ListAdsParams, priceBounds []int64) (*domain.AdFacets, error)
+	ReorderAdImages(ctx context.Context, userID, adID int64, imageIDs []int64) (*domain.Ad, error)
+	RemoveAdImage(ctx context.Context, userID, adID, imageID int64) (*domain.Ad, error)
+	ChangeAdStatus(ctx context.Context, userID, adID int64, status domain.AdStatus) (*domain.Ad, error)
+	RenewAd(ctx context.Context, userID, adID int64) (*domain.Ad, error)
+	BumpAd(ctx context.Context, userID, adID int64) (*domain.Ad, error)
+	GetAdHistory(ctx context.Context, userID, adID int64) ([]domain.AdVersion, error)
 }
 
 // AdsHandler handles HTTP requests for ads.
@@ -32,10 +51,34 @@ func NewAdsHandler(service AdsService, log *slog.Logger) *AdsHandler {
 
 // AdRequest defines the structure for an ad creation request.
 type AdRequest struct {
-	Title    string `json:"title"`
-	Text     string `json:"text"`
-	ImageURL string `json:"image_url,omitempty"`
-	Price    int64  `json:"price,omitempty"`
+	Title      string         `json:"title"`
+	Text       string         `json:"text"`
+	ImageURL   string         `json:"image_url,omitempty"` // cover image; must be the first of images when both are set
+	Images     []string       `json:"images,omitempty"`    // image URLs in display order, at most 10
+	Price      int64          `json:"price,omitempty"`     // in minor units of currency
+	Currency   string         `json:"currency,omitempty"`  // RUB (default), KZT or BYN
+	CategoryID int64          `json:"category_id"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+	Latitude   *float64       `json:"latitude,omitempty"`
+	Longitude  *float64       `json:"longitude,omitempty"`
+	City       string         `json:"city,omitempty"`
+	Status     string         `json:"status,omitempty"` // "draft" or "published" (default)
+}
+
+// AdPatchRequest defines the structure for a partial ad update request.
+// Omitted fields are left unchanged.
+type AdPatchRequest struct {
+	Title      *string        `json:"title,omitempty"`
+	Text       *string        `json:"text,omitempty"`
+	ImageURL   *string        `json:"image_url,omitempty"` // replaces the cover image
+	Images     []string       `json:"images,omitempty"`    // replaces all images when present
+	Price      *int64         `json:"price,omitempty"`
+	Currency   *string        `json:"currency,omitempty"`
+	CategoryID *int64         `json:"category_id,omitempty"`
+	Attributes map[string]any `json:"attributes,omitempty"` // replaces all attributes when present
+	Latitude   *float64       `json:"latitude,omitempty"`   // must be sent together with longitude
+	Longitude  *float64       `json:"longitude,omitempty"`
+	City       *string        `json:"city,omitempty"`
 }
 
 // CreateAd godoc
@@ -67,11 +110,25 @@ func (h *AdsHandler) CreateAd(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ad := &domain.Ad{
-		UserID:   userID,
-		Title:    req.Title,
-		Text:     req.Text,
-		ImageURL: req.ImageURL,
-		Price:    req.Price,
+		UserID:     userID,
+		Title:      req.Title,
+		Text:       req.Text,
+		ImageURL:   req.ImageURL,
+		Price:      req.Price,
+		Currency:   domain.Currency(req.Currency),
+		CategoryID: req.CategoryID,
+		Attributes: req.Attributes,
+		Latitude:   req.Latitude,
+		Longitude:  req.Longitude,
+		City:       req.City,
+		Status:     domain.AdStatus(req.Status),
+	}
+	if req.Images != nil {
+		if req.ImageURL != "" && (len(req.Images) == 0 || req.Images[0] != req.ImageURL) {
+			respondWithError(w, http.StatusBadRequest, "image_url must be the first of images")
+			return
+		}
+		ad.SetImageURLs(req.Images)
 	}
 
 	adID, err := h.service.CreateAd(r.Context(), ad)
@@ -88,19 +145,460 @@ func (h *AdsHandler) CreateAd(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetAd godoc
+// @Summary Get an ad
+// @Security ApiKeyAuth
+// @Description Returns a single ad by its ID. Drafts and archived ads are visible to their owner only.
+// @Tags ads
+// @Produce  json
+// @Param   id path int true "Ad ID"
+// @Success 200 {object} dto.AdResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ads/{id} [get]
+// GetAd handles requests to fetch a single ad.
+func (h *AdsHandler) GetAd(w http.ResponseWriter, r *http.Request) {
+	adID, err := parseAdID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ad, err := h.service.GetVisibleAd(r.Context(), currentUserID(r), adID)
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	resp := dto.ToAdResponse(ad, currentUserID(r))
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// GetAdHistory godoc
+// @Summary Get the edit history of an ad
+// @Security ApiKeyAuth
+// @Description Returns the title, text, price and cover an ad had before each of its edits, the most recent edit first. Available to the owner of the ad and to administrators.
+// @Tags ads
+// @Produce  json
+// @Param   id path int true "Ad ID"
+// @Success 200 {array} dto.AdVersionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ads/{id}/history [get]
+// GetAdHistory handles requests for the edit history of an ad.
+func (h *AdsHandler) GetAdHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	adID, err := parseAdID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	versions, err := h.service.GetAdHistory(r.Context(), userID, adID)
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	resp := dto.ToAdVersionResponseList(versions)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// UpdateAd godoc
+// @Summary Update an ad
+// @Security ApiKeyAuth
+// @Description Partially updates an ad owned by the authenticated user.
+// @Tags ads
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "Ad ID"
+// @Param   input body AdPatchRequest true "Fields to update"
+// @Success 200 {object} dto.AdResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ads/{id} [patch]
+// UpdateAd handles ad update requests.
+func (h *AdsHandler) UpdateAd(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	adID, err := parseAdID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req AdPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	patch := &domain.AdPatch{
+		Title:      req.Title,
+		Text:       req.Text,
+		ImageURL:   req.ImageURL,
+		Images:     req.Images,
+		Price:      req.Price,
+		CategoryID: req.CategoryID,
+		Attributes: req.Attributes,
+		City:       req.City,
+	}
+	if req.Currency != nil {
+		currency := domain.Currency(*req.Currency)
+		patch.Currency = &currency
+	}
+	if req.Images != nil && req.ImageURL != nil && (len(req.Images) == 0 || req.Images[0] != *req.ImageURL) {
+		respondWithError(w, http.StatusBadRequest, "image_url must be the first of images")
+		return
+	}
+	if req.Latitude != nil || req.Longitude != nil {
+		if req.Latitude == nil || req.Longitude == nil {
+			respondWithError(w, http.StatusBadRequest, "latitude and longitude must be set together")
+			return
+		}
+		patch.Location = &domain.GeoPoint{Lat: *req.Latitude, Lon: *req.Longitude}
+	}
+
+	ad, err := h.service.UpdateAd(r.Context(), userID, adID, patch)
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	resp := dto.ToAdResponse(ad, userID)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// DeleteAd godoc
+// @Summary Delete an ad
+// @Security ApiKeyAuth
+// @Description Deletes an ad owned by the authenticated user.
+// @Tags ads
+// @Param   id path int true "Ad ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ads/{id} [delete]
+// DeleteAd handles ad deletion requests.
+func (h *AdsHandler) DeleteAd(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	adID, err := parseAdID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.DeleteAd(r.Context(), userID, adID); err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdStatusRequest defines the target status of an ad status change.
+type AdStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// ChangeAdStatus godoc
+// @Summary Change ad status
+// @Security ApiKeyAuth
+// @Description Moves an ad owned by the authenticated user to another lifecycle status.
+// @Description Allowed transitions: draft → published, archived; published → reserved, sold, archived;
+// @Description reserved → published, sold, archived; sold → archived; archived → draft, published.
+// @Tags ads
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "Ad ID"
+// @Param   input body AdStatusRequest true "New status"
+// @Success 200 {object} dto.AdResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ads/{id}/status [post]
+// ChangeAdStatus handles ad status change requests.
+func (h *AdsHandler) ChangeAdStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	adID, err := parseAdID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req AdStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	ad, err := h.service.ChangeAdStatus(r.Context(), userID, adID, domain.AdStatus(req.Status))
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	resp := dto.ToAdResponse(ad, userID)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// RenewAd godoc
+// @Summary Renew an ad
+// @Security ApiKeyAuth
+// @Description Starts a new term for an ad owned by the authenticated user. Archived ads, including expired ones, are published again.
+// @Tags ads
+// @Produce  json
+// @Param   id path int true "Ad ID"
+// @Success 200 {object} dto.AdResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ads/{id}/renew [post]
+// RenewAd handles ad renewal requests.
+func (h *AdsHandler) RenewAd(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	adID, err := parseAdID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ad, err := h.service.RenewAd(r.Context(), userID, adID)
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	resp := dto.ToAdResponse(ad, userID)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// BumpAd godoc
+// @Summary Bump an ad
+// @Security ApiKeyAuth
+// @Description Moves a published ad owned by the authenticated user to the top of the feed. An ad can be bumped once per cooldown after it was posted or last bumped; earlier attempts return 409 with a Retry-After header.
+// @Tags ads
+// @Produce  json
+// @Param   id path int true "Ad ID"
+// @Success 200 {object} dto.AdResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Header  409 {integer} Retry-After "Seconds until the ad can be bumped again"
+// @Failure 500 {object} map[string]string
+// @Router /ads/{id}/bump [post]
+// BumpAd handles ad bump requests.
+func (h *AdsHandler) BumpAd(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	adID, err := parseAdID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ad, err := h.service.BumpAd(r.Context(), userID, adID)
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	resp := dto.ToAdResponse(ad, userID)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// ReorderImagesRequest defines the new order of the images of an ad.
+type ReorderImagesRequest struct {
+	ImageIDs []int64 `json:"image_ids"` // every image of the ad, the cover first
+}
+
+// ReorderAdImages godoc
+// @Summary Reorder ad images
+// @Security ApiKeyAuth
+// @Description Reorders the images of an ad owned by the authenticated user. The first image becomes the cover.
+// @Tags ads
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "Ad ID"
+// @Param   input body ReorderImagesRequest true "Image IDs in the new order"
+// @Success 200 {object} dto.AdResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ads/{id}/images/order [put]
+// ReorderAdImages handles requests to reorder the images of an ad.
+func (h *AdsHandler) ReorderAdImages(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	adID, err := parseAdID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req ReorderImagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	ad, err := h.service.ReorderAdImages(r.Context(), userID, adID, req.ImageIDs)
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	resp := dto.ToAdResponse(ad, userID)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// RemoveAdImage godoc
+// @Summary Remove an ad image
+// @Security ApiKeyAuth
+// @Description Removes an image from an ad owned by the authenticated user. Removing the cover makes the next image the cover.
+// @Tags ads
+// @Produce  json
+// @Param   id path int true "Ad ID"
+// @Param   imageID path int true "Image ID"
+// @Success 200 {object} dto.AdResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ads/{id}/images/{imageID} [delete]
+// RemoveAdImage handles requests to remove an image from an ad.
+func (h *AdsHandler) RemoveAdImage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	adID, err := parseAdID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	imageID, err := strconv.ParseInt(chi.URLParam(r, "imageID"), 10, 64)
+	if err != nil || imageID <= 0 {
+		respondWithError(w, http.StatusBadRequest, "invalid image id: must be a positive number")
+		return
+	}
+
+	ad, err := h.service.RemoveAdImage(r.Context(), userID, adID, imageID)
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	resp := dto.ToAdResponse(ad, userID)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
 // ListAds godoc
 // @Summary List ads
 // @Security ApiKeyAuth
 // @Description Returns a list of ads with pagination and filtering.
+// @Description By default the response is a bare JSON array. Send "Accept: application/vnd.marketplace.v2+json"
+// @Description to get the dto.AdListResponse envelope with total count, has_next and RFC 8288 Link headers.
 // @Tags ads
 // @Produce  json
-// @Param   sort_by query string false "Sort by field (price or created_at)" Enums(price, created_at)
+// @Produce  application/vnd.marketplace.v2+json
+// @Param   q query string false "Full-text search over title and text"
+// @Param   category query int false "Category ID filter, includes subcategories"
+// @Param   attr.{name} query string false "Attribute filter: attr.rooms=2 for equality, attr.year_min=2015 / attr.year_max=2020 for ranges"
+// @Param   near query string false "Search origin as latitude,longitude; adds distance_km to each ad"
+// @Param   radius_km query number false "Only ads within this distance from near (max 500)"
+// @Param   sort_by query string false "Sort by field (price, created_at, relevance when q is set, or distance when near is set); created_at is the time an ad was posted or last bumped" Enums(price, created_at, relevance, distance)
 // @Param   order query string false "Sort order (asc or desc)" Enums(asc, desc)
 // @Param   page query int false "Page number (1-based)"
 // @Param   limit query int false "Number of items per page (max 100)"
+// @Param   currency query string false "Currency to convert prices to, RUB by default, as display_price; min_price and max_price are in it" Enums(RUB, KZT, BYN)
 // @Param   min_price query int false "Minimum price filter"
 // @Param   max_price query int false "Maximum price filter"
+// @Param   price_dropped query bool false "Only ads whose latest price change is a drop"
+// @Param   cursor query string false "Keyset pagination cursor; pass it empty to start and then the returned next_cursor. Switches the response to dto.AdCursorPage"
+// @Param   status query string false "Comma-separated statuses; published by default, published and reserved are public"
+// @Param   mine query bool false "Only the ads of the authenticated user, in every status unless status is set"
 // @Success 200 {array} dto.AdResponse
+// @Header  200 {string} Link "Navigation links (envelope responses only)"
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /ads [get]
@@ -120,6 +618,11 @@ func (h *AdsHandler) ListAds(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if acceptsAdsEnvelope(r) {
+		h.listAdsPage(w, r, params)
+		return
+	}
+
 	ads, err := h.service.ListAds(r.Context(), params)
 	if err != nil {
 		handleServiceError(w, r, h.log, err)
@@ -139,10 +642,127 @@ func (h *AdsHandler) ListAds(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Convert to DTOs
-	adResponses := dto.ToAdResponseList(ads, currentUserID)
+	var resp any
+	if params.CursorMode {
+		resp = dto.ToAdCursorPage(ads, params, currentUserID)
+	} else {
+		resp = dto.ToAdResponseList(ads, currentUserID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// listAdsPage responds with the paginated envelope and navigation Link headers.
+func (h *AdsHandler) listAdsPage(w http.ResponseWriter, r *http.Request, params *domain.ListAdsParams) {
+	page, err := h.service.ListAdsPage(r.Context(), params)
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	resp := dto.ToAdListResponse(page, params, currentUserID(r))
+
+	if links := adsLinkHeader(r, resp, params.CursorMode); links != "" {
+		w.Header().Set("Link", links)
+	}
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("Content-Type", adsEnvelopeMediaType)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// SuggestAds godoc
+// @Summary Autocomplete ad titles
+// @Description Returns ad titles matching the prefix, tolerating typos.
+// @Tags ads
+// @Produce  json
+// @Param   prefix query string true "Title prefix typed by the user"
+// @Param   limit query int false "Number of suggestions (max 20)"
+// @Success 200 {object} dto.SuggestResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ads/suggest [get]
+// SuggestAds handles autocomplete requests for the search box.
+func (h *AdsHandler) SuggestAds(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var limit int
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid limit parameter: must be a number")
+			return
+		}
+	}
+
+	titles, err := h.service.SuggestTitles(r.Context(), query.Get("prefix"), limit)
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	resp := dto.SuggestResponse{Suggestions: titles}
+	if resp.Suggestions == nil {
+		resp.Suggestions = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// GetFacets godoc
+// @Summary Ad feed facets
+// @Description Returns ad counts per category, price bucket and author for the same filters as the feed.
+// @Description Category and price counts ignore their own filter; a category counts the ads of its subcategories too. Empty values are omitted.
+// @Tags ads
+// @Produce  json
+// @Param   q query string false "Full-text search over title and text"
+// @Param   category query int false "Category ID filter, includes subcategories"
+// @Param   currency query string false "Currency to convert prices to, RUB by default, as display_price; min_price and max_price are in it" Enums(RUB, KZT, BYN)
+// @Param   min_price query int false "Minimum price filter"
+// @Param   max_price query int false "Maximum price filter"
+// @Param   price_dropped query bool false "Only ads whose latest price change is a drop"
+// @Param   attr.{name} query string false "Attribute filter, as in the feed"
+// @Param   price_buckets query string false "Comma-separated ascending price histogram bounds in cents"
+// @Success 200 {object} dto.AdFacetsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ads/facets [get]
+// GetFacets handles requests for the feed filter sidebar counts.
+func (h *AdsHandler) GetFacets(w http.ResponseWriter, r *http.Request) {
+	params, err := h.parseListAdsParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var priceBounds []int64
+	if boundsStr := r.URL.Query().Get("price_buckets"); boundsStr != "" {
+		for _, part := range strings.Split(boundsStr, ",") {
+			bound, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "invalid price_buckets parameter: must be comma-separated numbers")
+				return
+			}
+			priceBounds = append(priceBounds, bound)
+		}
+	}
+
+	facets, err := h.service.GetFacets(r.Context(), params, priceBounds)
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
 
+	resp := dto.ToAdFacetsResponse(facets)
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(adResponses); err != nil {
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		h.log.Error("failed to encode response", slog.String("error", err.Error()))
 	}
 }
@@ -173,6 +793,63 @@ func (h *AdsHandler) parseListAdsParams(r *http.Request) (*domain.ListAdsParams,
 		params.Limit = limit
 	}
 
+	// Parse keyset pagination cursor
+	params.CursorMode = query.Has("cursor")
+	params.Cursor = query.Get("cursor")
+
+	// Parse full-text search query
+	params.Query = query.Get("q")
+
+	// Parse category filter
+	if categoryStr := query.Get("category"); categoryStr != "" {
+		categoryID, err := strconv.ParseInt(categoryStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid category parameter: must be a number")
+		}
+		params.CategoryID = &categoryID
+	}
+
+	// Parse structured attribute filters
+	params.Attributes = parseAttributeFilters(query)
+
+	// Parse location filter
+	if nearStr := query.Get("near"); nearStr != "" {
+		near, err := parseGeoPoint(nearStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid near parameter: must be latitude,longitude")
+		}
+		params.Near = near
+	}
+
+	if radiusStr := query.Get("radius_km"); radiusStr != "" {
+		radius, err := strconv.ParseFloat(radiusStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid radius_km parameter: must be a number")
+		}
+		params.RadiusKm = &radius
+	}
+
+	// Parse status filters
+	if statusStr := query.Get("status"); statusStr != "" {
+		for _, st := range strings.Split(statusStr, ",") {
+			params.Statuses = append(params.Statuses, domain.AdStatus(strings.TrimSpace(st)))
+		}
+	}
+
+	if mineStr := query.Get("mine"); mineStr != "" {
+		mine, err := strconv.ParseBool(mineStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mine parameter: must be a boolean")
+		}
+		if mine {
+			userID := currentUserID(r)
+			if userID == 0 {
+				return nil, fmt.Errorf("mine=true requires authentication")
+			}
+			params.OwnerID = &userID
+		}
+	}
+
 	// Parse price filter parameters
 	if minPriceStr := query.Get("min_price"); minPriceStr != "" {
 		minPrice, err := strconv.ParseInt(minPriceStr, 10, 64)
@@ -190,5 +867,74 @@ func (h *AdsHandler) parseListAdsParams(r *http.Request) (*domain.ListAdsParams,
 		params.MaxPrice = &maxPrice
 	}
 
+	if currency := query.Get("currency"); currency != "" {
+		params.Currency = domain.Currency(strings.ToUpper(currency))
+	}
+
+	if droppedStr := query.Get("price_dropped"); droppedStr != "" {
+		dropped, err := strconv.ParseBool(droppedStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price_dropped parameter: must be a boolean")
+		}
+		params.PriceDropped = dropped
+	}
+
 	return params, nil
 }
+
+// parseGeoPoint parses a "latitude,longitude" pair. Ranges are validated by the service layer.
+func parseGeoPoint(s string) (*domain.GeoPoint, error) {
+	latStr, lonStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return nil, fmt.Errorf("missing longitude")
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil {
+		return nil, err
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.GeoPoint{Lat: lat, Lon: lon}, nil
+}
+
+// parseAdID extracts the ad ID from the URL path.
+func parseAdID(r *http.Request) (int64, error) {
+	adID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || adID <= 0 {
+		return 0, fmt.Errorf("invalid ad id: must be a positive number")
+	}
+	return adID, nil
+}
+
+// currentUserID returns the authenticated user ID from the request context,
+// or 0 if the request is anonymous.
+func currentUserID(r *http.Request) int64 {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int64)
+	return userID
+}
+
+// parseAttributeFilters collects attr.<name>, attr.<name>_min and attr.<name>_max
+// query parameters. Names and values are validated by the service layer.
+func parseAttributeFilters(query url.Values) []domain.AttributeFilter {
+	keys := make([]string, 0)
+	for key := range query {
+		if strings.HasPrefix(key, attributeFilterPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	filters := make([]domain.AttributeFilter, 0, len(keys))
+	for _, key := range keys {
+		f := domain.AttributeFilter{Key: strings.TrimPrefix(key, attributeFilterPrefix), Op: "eq", Raw: query.Get(key)}
+		if name, ok := strings.CutSuffix(f.Key, "_min"); ok {
+			f.Key, f.Op = name, "min"
+		} else if name, ok := strings.CutSuffix(f.Key, "_max"); ok {
+			f.Key, f.Op = name, "max"
+		}
+		filters = append(filters, f)
+	}
+	return filters
+}
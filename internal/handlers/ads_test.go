@@ -9,23 +9,51 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/felix-kado/vk-test-task/internal/domain"
 	"github.com/felix-kado/vk-test-task/internal/middleware"
 	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 )
 
 // mockAdsService is a mock implementation of AdsService for testing.
 type mockAdsService struct {
-	CreateAdFunc func(ctx context.Context, ad *domain.Ad) (int64, error)
-	ListAdsFunc  func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error)
+	CreateAdFunc     func(ctx context.Context, ad *domain.Ad) (int64, error)
+	GetVisibleAdFunc func(ctx context.Context, viewerID, adID int64) (*domain.Ad, error)
+	UpdateAdFunc     func(ctx context.Context, userID, adID int64, patch *domain.AdPatch) (*domain.Ad, error)
+	DeleteAdFunc     func(ctx context.Context, userID, adID int64) error
+	ListAdsFunc      func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error)
+
+	ListAdsPageFunc   func(ctx context.Context, params *domain.ListAdsParams) (*domain.AdPage, error)
+	SuggestTitlesFunc func(ctx context.Context, prefix string, limit int) ([]string, error)
+	GetFacetsFunc     func(ctx context.Context, params *domain.ListAdsParams, priceBounds []int64) (*domain.AdFacets, error)
+
+	ReorderAdImagesFunc func(ctx context.Context, userID, adID int64, imageIDs []int64) (*domain.Ad, error)
+	RemoveAdImageFunc   func(ctx context.Context, userID, adID, imageID int64) (*domain.Ad, error)
+	ChangeAdStatusFunc  func(ctx context.Context, userID, adID int64, status domain.AdStatus) (*domain.Ad, error)
+	RenewAdFunc         func(ctx context.Context, userID, adID int64) (*domain.Ad, error)
+	BumpAdFunc          func(ctx context.Context, userID, adID int64) (*domain.Ad, error)
+	GetAdHistoryFunc    func(ctx context.Context, userID, adID int64) ([]domain.AdVersion, error)
 }
 
 func (m *mockAdsService) CreateAd(ctx context.Context, ad *domain.Ad) (int64, error) {
 	return m.CreateAdFunc(ctx, ad)
 }
 
+func (m *mockAdsService) GetVisibleAd(ctx context.Context, viewerID, adID int64) (*domain.Ad, error) {
+	return m.GetVisibleAdFunc(ctx, viewerID, adID)
+}
+
+func (m *mockAdsService) UpdateAd(ctx context.Context, userID, adID int64, patch *domain.AdPatch) (*domain.Ad, error) {
+	return m.UpdateAdFunc(ctx, userID, adID, patch)
+}
+
+func (m *mockAdsService) DeleteAd(ctx context.Context, userID, adID int64) error {
+	return m.DeleteAdFunc(ctx, userID, adID)
+}
+
 func (m *mockAdsService) ListAds(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
 	if m.ListAdsFunc != nil {
 		return m.ListAdsFunc(ctx, params)
@@ -33,6 +61,42 @@ func (m *mockAdsService) ListAds(ctx context.Context, params *domain.ListAdsPara
 	return nil, nil
 }
 
+func (m *mockAdsService) ListAdsPage(ctx context.Context, params *domain.ListAdsParams) (*domain.AdPage, error) {
+	return m.ListAdsPageFunc(ctx, params)
+}
+
+func (m *mockAdsService) SuggestTitles(ctx context.Context, prefix string, limit int) ([]string, error) {
+	return m.SuggestTitlesFunc(ctx, prefix, limit)
+}
+
+func (m *mockAdsService) GetFacets(ctx context.Context, params *domain.ListAdsParams, priceBounds []int64) (*domain.AdFacets, error) {
+	return m.GetFacetsFunc(ctx, params, priceBounds)
+}
+
+func (m *mockAdsService) ReorderAdImages(ctx context.Context, userID, adID int64, imageIDs []int64) (*domain.Ad, error) {
+	return m.ReorderAdImagesFunc(ctx, userID, adID, imageIDs)
+}
+
+func (m *mockAdsService) RemoveAdImage(ctx context.Context, userID, adID, imageID int64) (*domain.Ad, error) {
+	return m.RemoveAdImageFunc(ctx, userID, adID, imageID)
+}
+
+func (m *mockAdsService) ChangeAdStatus(ctx context.Context, userID, adID int64, status domain.AdStatus) (*domain.Ad, error) {
+	return m.ChangeAdStatusFunc(ctx, userID, adID, status)
+}
+
+func (m *mockAdsService) RenewAd(ctx context.Context, userID, adID int64) (*domain.Ad, error) {
+	return m.RenewAdFunc(ctx, userID, adID)
+}
+
+func (m *mockAdsService) BumpAd(ctx context.Context, userID, adID int64) (*domain.Ad, error) {
+	return m.BumpAdFunc(ctx, userID, adID)
+}
+
+func (m *mockAdsService) GetAdHistory(ctx context.Context, userID, adID int64) ([]domain.AdVersion, error) {
+	return m.GetAdHistoryFunc(ctx, userID, adID)
+}
+
 func TestAdsHandler_CreateAd(t *testing.T) {
 	type errorResponse struct {
 		Error string `json:"error"`
@@ -84,6 +148,54 @@ func TestAdsHandler_CreateAd(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   "invalid input: title cannot be empty",
 		},
+		{
+			name:   "draft ad",
+			userID: 1,
+			requestBody: AdRequest{
+				Title:  "Test Ad",
+				Text:   "This is a test ad.",
+				Status: "draft",
+			},
+			setupMock: func(m *mockAdsService) {
+				m.CreateAdFunc = func(ctx context.Context, ad *domain.Ad) (int64, error) {
+					assert.Equal(t, domain.AdStatusDraft, ad.Status)
+					return 123, nil
+				}
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":123}`,
+		},
+		{
+			name:   "ad with images",
+			userID: 1,
+			requestBody: AdRequest{
+				Title:  "Test Ad",
+				Text:   "This is a test ad.",
+				Images: []string{"https://images.example.com/1.jpg", "https://images.example.com/2.jpg"},
+			},
+			setupMock: func(m *mockAdsService) {
+				m.CreateAdFunc = func(ctx context.Context, ad *domain.Ad) (int64, error) {
+					assert.Equal(t, []string{"https://images.example.com/1.jpg", "https://images.example.com/2.jpg"}, ad.ImageURLs())
+					assert.Equal(t, "https://images.example.com/1.jpg", ad.ImageURL)
+					return 123, nil
+				}
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":123}`,
+		},
+		{
+			name:   "image_url is not the first of images",
+			userID: 1,
+			requestBody: AdRequest{
+				Title:    "Test Ad",
+				Text:     "This is a test ad.",
+				ImageURL: "https://images.example.com/2.jpg",
+				Images:   []string{"https://images.example.com/1.jpg", "https://images.example.com/2.jpg"},
+			},
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "image_url must be the first of images",
+		},
 	}
 
 	for _, tt := range tests {
@@ -116,98 +228,211 @@ func TestAdsHandler_CreateAd(t *testing.T) {
 	}
 }
 
-func TestAdsHandler_ListAds(t *testing.T) {
+// withURLParam attaches a chi route parameter to the request, as the router would.
+func withURLParam(req *http.Request, key, value string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add(key, value)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestAdsHandler_GetAd(t *testing.T) {
 	tests := []struct {
 		name                 string
-		queryParams          string
-		userID               int64 // For setting user in context
+		adID                 string
+		userID               int64
 		setupMock            func(*mockAdsService)
 		expectedStatus       int
 		expectedBody         string
 		expectedBodyContains []string
 	}{
 		{
-			name:        "Success - authorized user sees ownership",
-			queryParams: "",
-			userID:      1, // Authenticated user with ID 1
+			name:   "Success - owner sees ownership",
+			adID:   "101",
+			userID: 1,
 			setupMock: func(m *mockAdsService) {
-				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
-					return []domain.Ad{
-						{ID: 101, UserID: 1, Title: "My Own Ad", AuthorLogin: "test_user_1"},
-						{ID: 102, UserID: 2, Title: "Someone Else's Ad", AuthorLogin: "test_user_2"},
-					}, nil
+				m.GetVisibleAdFunc = func(ctx context.Context, viewerID, adID int64) (*domain.Ad, error) {
+					assert.Equal(t, int64(1), viewerID)
+					assert.Equal(t, int64(101), adID)
+					return &domain.Ad{ID: 101, UserID: 1, Title: "My Own Ad", AuthorLogin: "test_user_1"}, nil
 				}
 			},
-			expectedStatus: http.StatusOK,
-			expectedBodyContains: []string{
-				`"id":101`, `"title":"My Own Ad"`, `"is_owner":true`, `"author_login":"test_user_1"`,
-				`"id":102`, `"title":"Someone Else's Ad"`, `"is_owner":false`, `"author_login":"test_user_2"`,
-			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"id":101`, `"title":"My Own Ad"`, `"is_owner":true`},
 		},
 		{
-			name:        "Success - unauthorized user does not see ownership",
-			queryParams: "",
-			userID:      0, // No user in context
+			name:   "Success - anonymous user",
+			adID:   "101",
+			userID: 0,
 			setupMock: func(m *mockAdsService) {
-				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
-					return []domain.Ad{
-						{ID: 101, UserID: 1, Title: "An Ad", AuthorLogin: "test_user_1"},
-					}, nil
+				m.GetVisibleAdFunc = func(ctx context.Context, viewerID, adID int64) (*domain.Ad, error) {
+					assert.Equal(t, int64(0), viewerID)
+					return &domain.Ad{ID: 101, UserID: 1, Title: "An Ad", AuthorLogin: "test_user_1", Status: domain.AdStatusSold}, nil
 				}
 			},
-			expectedStatus: http.StatusOK,
-			expectedBodyContains: []string{
-				`"id":101`, `"title":"An Ad"`, `"is_owner":false`, `"author_login":"test_user_1"`,
-			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"id":101`, `"is_owner":false`, `"author_login":"test_user_1"`, `"status":"sold"`},
 		},
 		{
-			name:        "validation error from service",
-			queryParams: "?sort_by=invalid_field",
+			name: "Ad not found",
+			adID: "999",
 			setupMock: func(m *mockAdsService) {
-				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
-					return nil, fmt.Errorf("%w: invalid sort_by value", services.ErrInvalidInput)
+				m.GetVisibleAdFunc = func(ctx context.Context, viewerID, adID int64) (*domain.Ad, error) {
+					return nil, services.ErrAdNotFound
 				}
 			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"ad not found"}`,
+		},
+		{
+			name:           "Invalid ad id",
+			adID:           "abc",
+			setupMock:      func(m *mockAdsService) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid input: invalid sort_by value"}`,
+			expectedBody:   `{"error":"invalid ad id: must be a positive number"}`,
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAdsService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAdsHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodGet, "/ads/"+tt.adID, nil)
+			req = withURLParam(req, "id", tt.adID)
+			if tt.userID != 0 {
+				ctx := context.WithValue(req.Context(), middleware.UserIDKey, tt.userID)
+				req = req.WithContext(ctx)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.GetAd(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+			for _, sub := range tt.expectedBodyContains {
+				assert.Contains(t, rr.Body.String(), sub)
+			}
+		})
+	}
+}
+
+func TestAdsHandler_UpdateAd(t *testing.T) {
+	tests := []struct {
+		name                 string
+		adID                 string
+		userID               int64
+		requestBody          any
+		setupMock            func(*mockAdsService)
+		expectedStatus       int
+		expectedBody         string
+		expectedBodyContains []string
+	}{
 		{
-			name:        "Success with pagination and filtering",
-			queryParams: "?page=2&limit=5&min_price=100&max_price=500",
+			name:        "Success - partial update",
+			adID:        "101",
 			userID:      1,
+			requestBody: map[string]any{"price": 500},
 			setupMock: func(m *mockAdsService) {
-				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
-					assert.Equal(t, 2, params.Page)
-					assert.Equal(t, 5, params.Limit)
-					assert.Equal(t, int64(100), *params.MinPrice)
-					assert.Equal(t, int64(500), *params.MaxPrice)
-					return []domain.Ad{},
-						nil
+				m.UpdateAdFunc = func(ctx context.Context, userID, adID int64, patch *domain.AdPatch) (*domain.Ad, error) {
+					assert.Equal(t, int64(1), userID)
+					assert.Equal(t, int64(101), adID)
+					assert.Nil(t, patch.Title)
+					assert.Equal(t, int64(500), *patch.Price)
+					return &domain.Ad{ID: 101, UserID: 1, Title: "Ad", Price: 500}, nil
 				}
 			},
 			expectedStatus:       http.StatusOK,
-			expectedBodyContains: []string{"[]"},
+			expectedBodyContains: []string{`"id":101`, `"price":500`, `"is_owner":true`},
 		},
 		{
-			name:           "Invalid page parameter",
-			queryParams:    "?page=abc",
+			name:        "Forbidden - not the owner",
+			adID:        "101",
+			userID:      2,
+			requestBody: map[string]any{"title": "Hijacked"},
+			setupMock: func(m *mockAdsService) {
+				m.UpdateAdFunc = func(ctx context.Context, userID, adID int64, patch *domain.AdPatch) (*domain.Ad, error) {
+					return nil, services.ErrForbidden
+				}
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"forbidden"}`,
+		},
+		{
+			name:           "Unauthorized - no user in context",
+			adID:           "101",
+			requestBody:    map[string]any{"title": "New"},
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"unauthorized"}`,
+		},
+		{
+			name:           "Invalid request body",
+			adID:           "101",
+			userID:         1,
+			requestBody:    "not json",
 			setupMock:      func(m *mockAdsService) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid page parameter: must be a number"}`,
+			expectedBody:   `{"error":"invalid request body"}`,
 		},
 		{
-			name:           "Invalid limit parameter",
-			queryParams:    "?limit=abc",
+			name:        "Success - move location",
+			adID:        "101",
+			userID:      1,
+			requestBody: map[string]any{"latitude": 59.94, "longitude": 30.31, "city": "Saint Petersburg"},
+			setupMock: func(m *mockAdsService) {
+				m.UpdateAdFunc = func(ctx context.Context, userID, adID int64, patch *domain.AdPatch) (*domain.Ad, error) {
+					assert.Equal(t, &domain.GeoPoint{Lat: 59.94, Lon: 30.31}, patch.Location)
+					assert.Equal(t, "Saint Petersburg", *patch.City)
+					ad := &domain.Ad{ID: 101, UserID: 1, Title: "Ad"}
+					patch.Apply(ad)
+					return ad, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"latitude":59.94`, `"longitude":30.31`, `"city":"Saint Petersburg"`},
+		},
+		{
+			name:           "Latitude without longitude",
+			adID:           "101",
+			userID:         1,
+			requestBody:    map[string]any{"latitude": 59.94},
 			setupMock:      func(m *mockAdsService) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid limit parameter: must be a number"}`,
+			expectedBody:   `{"error":"latitude and longitude must be set together"}`,
 		},
 		{
-			name:           "Invalid min_price parameter",
-			queryParams:    "?min_price=abc",
+			name:        "Success - replace images",
+			adID:        "101",
+			userID:      1,
+			requestBody: map[string]any{"images": []string{"https://images.example.com/2.jpg", "https://images.example.com/1.jpg"}},
+			setupMock: func(m *mockAdsService) {
+				m.UpdateAdFunc = func(ctx context.Context, userID, adID int64, patch *domain.AdPatch) (*domain.Ad, error) {
+					assert.Equal(t, []string{"https://images.example.com/2.jpg", "https://images.example.com/1.jpg"}, patch.Images)
+					ad := &domain.Ad{ID: 101, UserID: 1, Title: "Ad"}
+					patch.Apply(ad)
+					return ad, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBodyContains: []string{
+				`"image_url":"https://images.example.com/2.jpg"`,
+				`"images":[{"id":0,"url":"https://images.example.com/2.jpg"},{"id":0,"url":"https://images.example.com/1.jpg"}]`,
+				`"cover":{"id":0,"url":"https://images.example.com/2.jpg"}`,
+			},
+		},
+		{
+			name:           "image_url conflicts with images",
+			adID:           "101",
+			userID:         1,
+			requestBody:    map[string]any{"image_url": "https://images.example.com/1.jpg", "images": []string{}},
 			setupMock:      func(m *mockAdsService) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid min_price parameter: must be a number"}`,
+			expectedBody:   `{"error":"image_url must be the first of images"}`,
 		},
 	}
 
@@ -218,27 +443,1133 @@ func TestAdsHandler_ListAds(t *testing.T) {
 
 			handler := NewAdsHandler(mockSvc, slog.Default())
 
-			req := httptest.NewRequest(http.MethodGet, "/ads"+tt.queryParams, nil)
-			// Add user ID to context if provided for the test case
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPatch, "/ads/"+tt.adID, bytes.NewReader(body))
+			req = withURLParam(req, "id", tt.adID)
 			if tt.userID != 0 {
 				ctx := context.WithValue(req.Context(), middleware.UserIDKey, tt.userID)
 				req = req.WithContext(ctx)
 			}
 
 			rr := httptest.NewRecorder()
-
-			handler.ListAds(rr, req)
+			handler.UpdateAd(rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code)
 
 			if tt.expectedBody != "" {
 				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
-			} else if len(tt.expectedBodyContains) > 0 {
-				bodyStr := rr.Body.String()
-				for _, sub := range tt.expectedBodyContains {
-					assert.Contains(t, bodyStr, sub)
+			}
+			for _, sub := range tt.expectedBodyContains {
+				assert.Contains(t, rr.Body.String(), sub)
+			}
+		})
+	}
+}
+
+func TestAdsHandler_DeleteAd(t *testing.T) {
+	tests := []struct {
+		name           string
+		adID           string
+		userID         int64
+		setupMock      func(*mockAdsService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Success",
+			adID:   "101",
+			userID: 1,
+			setupMock: func(m *mockAdsService) {
+				m.DeleteAdFunc = func(ctx context.Context, userID, adID int64) error {
+					return nil
+				}
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Forbidden - not the owner",
+			adID:   "101",
+			userID: 2,
+			setupMock: func(m *mockAdsService) {
+				m.DeleteAdFunc = func(ctx context.Context, userID, adID int64) error {
+					return services.ErrForbidden
+				}
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"forbidden"}`,
+		},
+		{
+			name:   "Ad not found",
+			adID:   "999",
+			userID: 1,
+			setupMock: func(m *mockAdsService) {
+				m.DeleteAdFunc = func(ctx context.Context, userID, adID int64) error {
+					return services.ErrAdNotFound
 				}
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"ad not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAdsService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAdsHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodDelete, "/ads/"+tt.adID, nil)
+			req = withURLParam(req, "id", tt.adID)
+			ctx := context.WithValue(req.Context(), middleware.UserIDKey, tt.userID)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			handler.DeleteAd(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
 			}
 		})
 	}
 }
+
+func TestAdsHandler_ChangeAdStatus(t *testing.T) {
+	tests := []struct {
+		name                 string
+		userID               int64
+		requestBody          any
+		setupMock            func(*mockAdsService)
+		expectedStatus       int
+		expectedBody         string
+		expectedBodyContains []string
+	}{
+		{
+			name:        "Success",
+			userID:      1,
+			requestBody: AdStatusRequest{Status: "sold"},
+			setupMock: func(m *mockAdsService) {
+				m.ChangeAdStatusFunc = func(ctx context.Context, userID, adID int64, status domain.AdStatus) (*domain.Ad, error) {
+					assert.Equal(t, int64(1), userID)
+					assert.Equal(t, int64(101), adID)
+					assert.Equal(t, domain.AdStatusSold, status)
+					return &domain.Ad{ID: 101, UserID: 1, Status: domain.AdStatusSold}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"id":101`, `"status":"sold"`},
+		},
+		{
+			name:        "Transition not allowed",
+			userID:      1,
+			requestBody: AdStatusRequest{Status: "reserved"},
+			setupMock: func(m *mockAdsService) {
+				m.ChangeAdStatusFunc = func(ctx context.Context, userID, adID int64, status domain.AdStatus) (*domain.Ad, error) {
+					return nil, fmt.Errorf("%w: cannot change status from sold to reserved", services.ErrConflict)
+				}
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":"resource conflict: cannot change status from sold to reserved"}`,
+		},
+		{
+			name:        "Forbidden - not the owner",
+			userID:      2,
+			requestBody: AdStatusRequest{Status: "sold"},
+			setupMock: func(m *mockAdsService) {
+				m.ChangeAdStatusFunc = func(ctx context.Context, userID, adID int64, status domain.AdStatus) (*domain.Ad, error) {
+					return nil, services.ErrForbidden
+				}
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"forbidden"}`,
+		},
+		{
+			name:           "Unauthorized",
+			requestBody:    AdStatusRequest{Status: "sold"},
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"unauthorized"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAdsService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAdsHandler(mockSvc, slog.Default())
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/ads/101/status", bytes.NewReader(body))
+			req = withURLParam(req, "id", "101")
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ChangeAdStatus(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+			for _, sub := range tt.expectedBodyContains {
+				assert.Contains(t, rr.Body.String(), sub)
+			}
+		})
+	}
+}
+
+func TestAdsHandler_RenewAd(t *testing.T) {
+	expiresAt := time.Date(2026, 11, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		userID               int64
+		setupMock            func(*mockAdsService)
+		expectedStatus       int
+		expectedBody         string
+		expectedBodyContains []string
+	}{
+		{
+			name:   "Success",
+			userID: 1,
+			setupMock: func(m *mockAdsService) {
+				m.RenewAdFunc = func(ctx context.Context, userID, adID int64) (*domain.Ad, error) {
+					assert.Equal(t, int64(1), userID)
+					assert.Equal(t, int64(101), adID)
+					return &domain.Ad{ID: 101, UserID: 1, Status: domain.AdStatusPublished, ExpiresAt: expiresAt}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"status":"published"`, `"expires_at":"2026-11-15T12:00:00Z"`},
+		},
+		{
+			name:   "Sold ad",
+			userID: 1,
+			setupMock: func(m *mockAdsService) {
+				m.RenewAdFunc = func(ctx context.Context, userID, adID int64) (*domain.Ad, error) {
+					return nil, fmt.Errorf("%w: a sold ad cannot be renewed", services.ErrConflict)
+				}
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":"resource conflict: a sold ad cannot be renewed"}`,
+		},
+		{
+			name:           "Unauthorized",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"unauthorized"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAdsService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAdsHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodPost, "/ads/101/renew", nil)
+			req = withURLParam(req, "id", "101")
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.RenewAd(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+			for _, sub := range tt.expectedBodyContains {
+				assert.Contains(t, rr.Body.String(), sub)
+			}
+		})
+	}
+}
+
+func TestAdsHandler_BumpAd(t *testing.T) {
+	bumpedAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		userID             int64
+		setupMock          func(*mockAdsService)
+		expectedStatus     int
+		expectedBody       string
+		expectedRetryAfter string
+	}{
+		{
+			name:   "Success",
+			userID: 1,
+			setupMock: func(m *mockAdsService) {
+				m.BumpAdFunc = func(ctx context.Context, userID, adID int64) (*domain.Ad, error) {
+					assert.Equal(t, int64(1), userID)
+					assert.Equal(t, int64(101), adID)
+					return &domain.Ad{ID: 101, UserID: 1, Status: domain.AdStatusPublished, BumpedAt: bumpedAt}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Cooldown",
+			userID: 1,
+			setupMock: func(m *mockAdsService) {
+				m.BumpAdFunc = func(ctx context.Context, userID, adID int64) (*domain.Ad, error) {
+					return nil, &services.RetryAfterError{
+						Err:   fmt.Errorf("%w: the ad can be bumped again in 1h0m0s", services.ErrConflict),
+						After: time.Hour - 500*time.Millisecond,
+					}
+				}
+			},
+			expectedStatus:     http.StatusConflict,
+			expectedBody:       `{"error":"resource conflict: the ad can be bumped again in 1h0m0s"}`,
+			expectedRetryAfter: "3600",
+		},
+		{
+			name:   "Not the owner",
+			userID: 2,
+			setupMock: func(m *mockAdsService) {
+				m.BumpAdFunc = func(ctx context.Context, userID, adID int64) (*domain.Ad, error) {
+					return nil, services.ErrForbidden
+				}
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"forbidden"}`,
+		},
+		{
+			name:           "Unauthorized",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"unauthorized"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAdsService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAdsHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodPost, "/ads/101/bump", nil)
+			req = withURLParam(req, "id", "101")
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.BumpAd(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			} else {
+				assert.Contains(t, rr.Body.String(), `"bumped_at":"2026-10-16T12:00:00Z"`)
+			}
+			assert.Equal(t, tt.expectedRetryAfter, rr.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestAdsHandler_GetAdHistory(t *testing.T) {
+	editedAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	editorID := int64(1)
+
+	tests := []struct {
+		name           string
+		userID         int64
+		setupMock      func(*mockAdsService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Success",
+			userID: 1,
+			setupMock: func(m *mockAdsService) {
+				m.GetAdHistoryFunc = func(ctx context.Context, userID, adID int64) ([]domain.AdVersion, error) {
+					assert.Equal(t, int64(1), userID)
+					assert.Equal(t, int64(101), adID)
+					return []domain.AdVersion{
+						{ID: 5, AdID: 101, Title: "Bicycle", Text: "Like new", Price: 1000, Currency: domain.CurrencyRUB, EditorID: &editorID, EditorLogin: "seller", EditedAt: editedAt},
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":5,"title":"Bicycle","text":"Like new","price":1000,"currency":"RUB","editor_id":1,"editor_login":"seller","edited_at":"2026-10-16T12:00:00Z"}]`,
+		},
+		{
+			name:   "Never edited",
+			userID: 1,
+			setupMock: func(m *mockAdsService) {
+				m.GetAdHistoryFunc = func(ctx context.Context, userID, adID int64) ([]domain.AdVersion, error) {
+					return nil, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:   "Not the owner",
+			userID: 2,
+			setupMock: func(m *mockAdsService) {
+				m.GetAdHistoryFunc = func(ctx context.Context, userID, adID int64) ([]domain.AdVersion, error) {
+					return nil, services.ErrForbidden
+				}
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"forbidden"}`,
+		},
+		{
+			name:           "Unauthorized",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"unauthorized"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAdsService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAdsHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodGet, "/ads/101/history", nil)
+			req = withURLParam(req, "id", "101")
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.GetAdHistory(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestAdsHandler_ReorderAdImages(t *testing.T) {
+	tests := []struct {
+		name                 string
+		userID               int64
+		requestBody          any
+		setupMock            func(*mockAdsService)
+		expectedStatus       int
+		expectedBody         string
+		expectedBodyContains []string
+	}{
+		{
+			name:        "Success",
+			userID:      1,
+			requestBody: ReorderImagesRequest{ImageIDs: []int64{12, 11}},
+			setupMock: func(m *mockAdsService) {
+				m.ReorderAdImagesFunc = func(ctx context.Context, userID, adID int64, imageIDs []int64) (*domain.Ad, error) {
+					assert.Equal(t, int64(1), userID)
+					assert.Equal(t, int64(101), adID)
+					assert.Equal(t, []int64{12, 11}, imageIDs)
+					return &domain.Ad{ID: 101, UserID: 1, ImageURL: "https://images.example.com/2.jpg", Images: []domain.AdImage{
+						{ID: 12, URL: "https://images.example.com/2.jpg"},
+						{ID: 11, URL: "https://images.example.com/1.jpg", Position: 1},
+					}}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"cover":{"id":12,"url":"https://images.example.com/2.jpg"}`},
+		},
+		{
+			name:        "Incomplete order",
+			userID:      1,
+			requestBody: ReorderImagesRequest{ImageIDs: []int64{12}},
+			setupMock: func(m *mockAdsService) {
+				m.ReorderAdImagesFunc = func(ctx context.Context, userID, adID int64, imageIDs []int64) (*domain.Ad, error) {
+					return nil, fmt.Errorf("%w: image_ids must list every image of the ad once", services.ErrInvalidInput)
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid input: image_ids must list every image of the ad once"}`,
+		},
+		{
+			name:           "Invalid request body",
+			userID:         1,
+			requestBody:    "not json",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid request body"}`,
+		},
+		{
+			name:           "Unauthorized",
+			requestBody:    ReorderImagesRequest{ImageIDs: []int64{12, 11}},
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"unauthorized"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAdsService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAdsHandler(mockSvc, slog.Default())
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPut, "/ads/101/images/order", bytes.NewReader(body))
+			req = withURLParam(req, "id", "101")
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ReorderAdImages(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+			for _, sub := range tt.expectedBodyContains {
+				assert.Contains(t, rr.Body.String(), sub)
+			}
+		})
+	}
+}
+
+func TestAdsHandler_RemoveAdImage(t *testing.T) {
+	tests := []struct {
+		name                 string
+		imageID              string
+		setupMock            func(*mockAdsService)
+		expectedStatus       int
+		expectedBody         string
+		expectedBodyContains []string
+	}{
+		{
+			name:    "Success",
+			imageID: "11",
+			setupMock: func(m *mockAdsService) {
+				m.RemoveAdImageFunc = func(ctx context.Context, userID, adID, imageID int64) (*domain.Ad, error) {
+					assert.Equal(t, int64(1), userID)
+					assert.Equal(t, int64(101), adID)
+					assert.Equal(t, int64(11), imageID)
+					return &domain.Ad{ID: 101, UserID: 1}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"id":101`, `"image_url":""`},
+		},
+		{
+			name:    "Image not found",
+			imageID: "99",
+			setupMock: func(m *mockAdsService) {
+				m.RemoveAdImageFunc = func(ctx context.Context, userID, adID, imageID int64) (*domain.Ad, error) {
+					return nil, services.ErrImageNotFound
+				}
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"image not found"}`,
+		},
+		{
+			name:           "Invalid image ID",
+			imageID:        "abc",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid image id: must be a positive number"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAdsService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAdsHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodDelete, "/ads/101/images/"+tt.imageID, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "101")
+			rctx.URLParams.Add("imageID", tt.imageID)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			req = req.WithContext(context.WithValue(ctx, middleware.UserIDKey, int64(1)))
+
+			rr := httptest.NewRecorder()
+			handler.RemoveAdImage(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+			for _, sub := range tt.expectedBodyContains {
+				assert.Contains(t, rr.Body.String(), sub)
+			}
+		})
+	}
+}
+
+func TestAdsHandler_ListAds(t *testing.T) {
+	tests := []struct {
+		name                    string
+		queryParams             string
+		userID                  int64 // For setting user in context
+		setupMock               func(*mockAdsService)
+		expectedStatus          int
+		expectedBody            string
+		expectedBodyContains    []string
+		expectedBodyNotContains []string
+	}{
+		{
+			name:        "Success - authorized user sees ownership",
+			queryParams: "",
+			userID:      1, // Authenticated user with ID 1
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					return []domain.Ad{
+						{ID: 101, UserID: 1, Title: "My Own Ad", AuthorLogin: "test_user_1"},
+						{ID: 102, UserID: 2, Title: "Someone Else's Ad", AuthorLogin: "test_user_2"},
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBodyContains: []string{
+				`"id":101`, `"title":"My Own Ad"`, `"is_owner":true`, `"author_login":"test_user_1"`,
+				`"id":102`, `"title":"Someone Else's Ad"`, `"is_owner":false`, `"author_login":"test_user_2"`,
+			},
+		},
+		{
+			name:        "Success - unauthorized user does not see ownership",
+			queryParams: "",
+			userID:      0, // No user in context
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					return []domain.Ad{
+						{ID: 101, UserID: 1, Title: "An Ad", AuthorLogin: "test_user_1"},
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBodyContains: []string{
+				`"id":101`, `"title":"An Ad"`, `"is_owner":false`, `"author_login":"test_user_1"`,
+			},
+		},
+		{
+			name:        "validation error from service",
+			queryParams: "?sort_by=invalid_field",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					return nil, fmt.Errorf("%w: invalid sort_by value", services.ErrInvalidInput)
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid input: invalid sort_by value"}`,
+		},
+		{
+			name:        "Success with pagination and filtering",
+			queryParams: "?page=2&limit=5&min_price=100&max_price=500",
+			userID:      1,
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					assert.Equal(t, 2, params.Page)
+					assert.Equal(t, 5, params.Limit)
+					assert.Equal(t, int64(100), *params.MinPrice)
+					assert.Equal(t, int64(500), *params.MaxPrice)
+					return []domain.Ad{},
+						nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{"[]"},
+		},
+		{
+			name:        "Success - cursor mode returns next_cursor when there is a next page",
+			queryParams: "?cursor=&limit=2",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					assert.True(t, params.CursorMode)
+					params.SetDefaults()
+					return []domain.Ad{
+						{ID: 5, Price: 100},
+						{ID: 4, Price: 200},
+						{ID: 3, Price: 300},
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBodyContains: []string{
+				`"items":[`, `"id":5`, `"id":4`,
+				`"next_cursor":"` + domain.NewAdCursor(&domain.Ad{ID: 4, Price: 200}, "created_at", "desc").Encode() + `"`,
+			},
+			expectedBodyNotContains: []string{`"id":3`},
+		},
+		{
+			name:        "Success - cursor mode omits next_cursor on an exactly full last page",
+			queryParams: "?cursor=&limit=2",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					params.SetDefaults()
+					return []domain.Ad{{ID: 5}, {ID: 4}}, nil
+				}
+			},
+			expectedStatus:          http.StatusOK,
+			expectedBodyContains:    []string{`"items":[`, `"id":5`, `"id":4`},
+			expectedBodyNotContains: []string{"next_cursor"},
+		},
+		{
+			name:        "Success - cursor mode omits next_cursor on the last page",
+			queryParams: "?cursor=abc&limit=10",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					assert.Equal(t, "abc", params.Cursor)
+					params.SetDefaults()
+					return []domain.Ad{{ID: 1}}, nil
+				}
+			},
+			expectedStatus:          http.StatusOK,
+			expectedBodyContains:    []string{`"items":[`, `"id":1`},
+			expectedBodyNotContains: []string{"next_cursor"},
+		},
+		{
+			name:        "Success - price drops",
+			queryParams: "?price_dropped=true",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					assert.True(t, params.PriceDropped)
+					previous := int64(1000)
+					droppedAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+					return []domain.Ad{{ID: 1, Price: 800, PreviousPrice: &previous, PriceDroppedAt: &droppedAt}}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"price":800`, `"previous_price":1000`, `"price_dropped_at":"2026-10-16T12:00:00Z"`},
+		},
+		{
+			name:        "Success - prices in another currency",
+			queryParams: "?currency=kzt&max_price=500000",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					assert.Equal(t, domain.CurrencyKZT, params.Currency)
+					assert.Equal(t, int64(500000), *params.MaxPrice)
+					displayPrice := int64(473684)
+					return []domain.Ad{{ID: 1, Price: 90000, Currency: domain.CurrencyRUB, DisplayPrice: &displayPrice, DisplayCurrency: domain.CurrencyKZT}}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"price":90000`, `"currency":"RUB"`, `"display_price":473684`, `"display_currency":"KZT"`},
+		},
+		{
+			name:           "Invalid price_dropped",
+			queryParams:    "?price_dropped=maybe",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid price_dropped parameter: must be a boolean"}`,
+		},
+		{
+			name:        "Success with full-text search",
+			queryParams: "?q=red+bike&sort_by=relevance&max_price=500",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					assert.Equal(t, "red bike", params.Query)
+					assert.Equal(t, "relevance", params.SortBy)
+					assert.Equal(t, int64(500), *params.MaxPrice)
+					return []domain.Ad{}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{"[]"},
+		},
+		{
+			name:        "Success with category filter",
+			queryParams: "?category=3",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					assert.Equal(t, int64(3), *params.CategoryID)
+					return []domain.Ad{{ID: 1, CategoryID: 7}}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"category_id":7`},
+		},
+		{
+			name:        "Success with attribute filters",
+			queryParams: "?category=4&attr.rooms=2&attr.area_min=40&attr.area_max=80",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					assert.Equal(t, []domain.AttributeFilter{
+						{Key: "area", Op: "max", Raw: "80"},
+						{Key: "area", Op: "min", Raw: "40"},
+						{Key: "rooms", Op: "eq", Raw: "2"},
+					}, params.Attributes)
+					return []domain.Ad{{ID: 1, Attributes: map[string]any{"rooms": 2}}}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"attributes":{"rooms":2}`},
+		},
+		{
+			name:           "Invalid category parameter",
+			queryParams:    "?category=cars",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid category parameter: must be a number"}`,
+		},
+		{
+			name:           "Invalid page parameter",
+			queryParams:    "?page=abc",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid page parameter: must be a number"}`,
+		},
+		{
+			name:           "Invalid limit parameter",
+			queryParams:    "?limit=abc",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid limit parameter: must be a number"}`,
+		},
+		{
+			name:           "Invalid min_price parameter",
+			queryParams:    "?min_price=abc",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid min_price parameter: must be a number"}`,
+		},
+		{
+			name:        "Success - near location adds distance",
+			queryParams: "?near=55.75,37.62&radius_km=5&sort_by=distance",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					assert.Equal(t, &domain.GeoPoint{Lat: 55.75, Lon: 37.62}, params.Near)
+					assert.Equal(t, 5.0, *params.RadiusKm)
+					lat, lon, distance := 55.76, 37.63, 1.23456
+					return []domain.Ad{
+						{ID: 101, Title: "Nearby", City: "Moscow", Latitude: &lat, Longitude: &lon, DistanceKm: &distance},
+					}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"city":"Moscow"`, `"latitude":55.76`, `"distance_km":1.23`},
+		},
+		{
+			name:           "Invalid near parameter",
+			queryParams:    "?near=55.75",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid near parameter: must be latitude,longitude"}`,
+		},
+		{
+			name:           "Invalid radius_km parameter",
+			queryParams:    "?near=55.75,37.62&radius_km=far",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid radius_km parameter: must be a number"}`,
+		},
+		{
+			name:        "Status filter",
+			queryParams: "?status=published,reserved",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					assert.Equal(t, []domain.AdStatus{domain.AdStatusPublished, domain.AdStatusReserved}, params.Statuses)
+					assert.Nil(t, params.OwnerID)
+					return []domain.Ad{}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:        "Own ads",
+			queryParams: "?mine=true&status=draft",
+			userID:      1,
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsFunc = func(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+					if assert.NotNil(t, params.OwnerID) {
+						assert.Equal(t, int64(1), *params.OwnerID)
+					}
+					assert.Equal(t, []domain.AdStatus{domain.AdStatusDraft}, params.Statuses)
+					return []domain.Ad{{ID: 101, UserID: 1, Title: "Draft", Status: domain.AdStatusDraft}}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"status":"draft"`, `"is_owner":true`},
+		},
+		{
+			name:           "Own ads require authentication",
+			queryParams:    "?mine=true",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"mine=true requires authentication"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAdsService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAdsHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodGet, "/ads"+tt.queryParams, nil)
+			// Add user ID to context if provided for the test case
+			if tt.userID != 0 {
+				ctx := context.WithValue(req.Context(), middleware.UserIDKey, tt.userID)
+				req = req.WithContext(ctx)
+			}
+
+			rr := httptest.NewRecorder()
+
+			handler.ListAds(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			} else if len(tt.expectedBodyContains) > 0 {
+				bodyStr := rr.Body.String()
+				for _, sub := range tt.expectedBodyContains {
+					assert.Contains(t, bodyStr, sub)
+				}
+			}
+			for _, sub := range tt.expectedBodyNotContains {
+				assert.NotContains(t, rr.Body.String(), sub)
+			}
+		})
+	}
+}
+
+func TestAdsHandler_ListAds_Envelope(t *testing.T) {
+	tests := []struct {
+		name                 string
+		queryParams          string
+		setupMock            func(*mockAdsService)
+		expectedStatus       int
+		expectedLink         string
+		expectedBodyContains []string
+	}{
+		{
+			name:        "Middle page has all navigation links",
+			queryParams: "?page=2&limit=2&min_price=100",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsPageFunc = func(ctx context.Context, params *domain.ListAdsParams) (*domain.AdPage, error) {
+					assert.Equal(t, int64(100), *params.MinPrice)
+					params.SetDefaults()
+					return &domain.AdPage{Ads: []domain.Ad{{ID: 3}, {ID: 4}}, Total: 5}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedLink: `</ads?limit=2&min_price=100&page=1>; rel="first", ` +
+				`</ads?limit=2&min_price=100&page=1>; rel="prev", ` +
+				`</ads?limit=2&min_price=100&page=3>; rel="next", ` +
+				`</ads?limit=2&min_price=100&page=3>; rel="last"`,
+			expectedBodyContains: []string{`"items":[`, `"page":2`, `"limit":2`, `"total":5`, `"has_next":true`},
+		},
+		{
+			name:        "Last page has no next link",
+			queryParams: "?page=3&limit=2",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsPageFunc = func(ctx context.Context, params *domain.ListAdsParams) (*domain.AdPage, error) {
+					params.SetDefaults()
+					return &domain.AdPage{Ads: []domain.Ad{{ID: 5}}, Total: 5}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedLink:         `</ads?limit=2&page=1>; rel="first", </ads?limit=2&page=2>; rel="prev", </ads?limit=2&page=3>; rel="last"`,
+			expectedBodyContains: []string{`"total":5`, `"has_next":false`},
+		},
+		{
+			name:        "Cursor mode on an exactly full last page has no next link",
+			queryParams: "?cursor=&limit=2",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsPageFunc = func(ctx context.Context, params *domain.ListAdsParams) (*domain.AdPage, error) {
+					assert.True(t, params.CursorMode)
+					params.SetDefaults()
+					return &domain.AdPage{Ads: []domain.Ad{{ID: 2}, {ID: 1}}, Total: 2}, nil
+				}
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: []string{`"id":2`, `"id":1`, `"has_next":false`},
+		},
+		{
+			name:        "Service error",
+			queryParams: "?sort_by=name",
+			setupMock: func(m *mockAdsService) {
+				m.ListAdsPageFunc = func(ctx context.Context, params *domain.ListAdsParams) (*domain.AdPage, error) {
+					return nil, fmt.Errorf("%w: invalid sort_by value", services.ErrInvalidInput)
+				}
+			},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: []string{"invalid sort_by value"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAdsService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAdsHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodGet, "/ads"+tt.queryParams, nil)
+			req.Header.Set("Accept", adsEnvelopeMediaType)
+
+			rr := httptest.NewRecorder()
+			handler.ListAds(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedLink, rr.Header().Get("Link"))
+			for _, sub := range tt.expectedBodyContains {
+				assert.Contains(t, rr.Body.String(), sub)
+			}
+		})
+	}
+}
+
+func TestAdsHandler_SuggestAds(t *testing.T) {
+	tests := []struct {
+		name           string
+		queryParams    string
+		setupMock      func(*mockAdsService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "Success",
+			queryParams: "?prefix=bic&limit=5",
+			setupMock: func(m *mockAdsService) {
+				m.SuggestTitlesFunc = func(ctx context.Context, prefix string, limit int) ([]string, error) {
+					assert.Equal(t, "bic", prefix)
+					assert.Equal(t, 5, limit)
+					return []string{"Bicycle", "Bicycle helmet"}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"suggestions":["Bicycle","Bicycle helmet"]}`,
+		},
+		{
+			name:        "No matches returns an empty list",
+			queryParams: "?prefix=zzz",
+			setupMock: func(m *mockAdsService) {
+				m.SuggestTitlesFunc = func(ctx context.Context, prefix string, limit int) ([]string, error) {
+					return nil, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"suggestions":[]}`,
+		},
+		{
+			name:        "Missing prefix",
+			queryParams: "",
+			setupMock: func(m *mockAdsService) {
+				m.SuggestTitlesFunc = func(ctx context.Context, prefix string, limit int) ([]string, error) {
+					return nil, fmt.Errorf("%w: prefix is required", services.ErrInvalidInput)
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid input: prefix is required"}`,
+		},
+		{
+			name:           "Invalid limit parameter",
+			queryParams:    "?prefix=bic&limit=abc",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid limit parameter: must be a number"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAdsService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAdsHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodGet, "/ads/suggest"+tt.queryParams, nil)
+			rr := httptest.NewRecorder()
+			handler.SuggestAds(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestAdsHandler_GetFacets(t *testing.T) {
+	tests := []struct {
+		name           string
+		queryParams    string
+		setupMock      func(*mockAdsService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "Success",
+			queryParams: "?category=3&min_price=100&price_buckets=0,%201000",
+			setupMock: func(m *mockAdsService) {
+				m.GetFacetsFunc = func(ctx context.Context, params *domain.ListAdsParams, priceBounds []int64) (*domain.AdFacets, error) {
+					assert.Equal(t, int64(3), *params.CategoryID)
+					assert.Equal(t, int64(100), *params.MinPrice)
+					assert.Equal(t, []int64{0, 1000}, priceBounds)
+					max := int64(1000)
+					return &domain.AdFacets{
+						Categories:   []domain.FacetCount{{ID: 3, Label: "Cars", Count: 2}},
+						PriceBuckets: []domain.PriceBucket{{Min: 0, Max: &max, Count: 1}, {Min: 1000, Count: 1}},
+						Authors:      []domain.FacetCount{{ID: 1, Label: "seller", Count: 2}},
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{
+				"categories":[{"id":3,"name":"Cars","count":2}],
+				"price_buckets":[{"min":0,"max":1000,"count":1},{"min":1000,"count":1}],
+				"authors":[{"login":"seller","count":2}]
+			}`,
+		},
+		{
+			name:        "Empty facets are empty lists",
+			queryParams: "",
+			setupMock: func(m *mockAdsService) {
+				m.GetFacetsFunc = func(ctx context.Context, params *domain.ListAdsParams, priceBounds []int64) (*domain.AdFacets, error) {
+					assert.Nil(t, priceBounds)
+					return &domain.AdFacets{}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"categories":[],"price_buckets":[],"authors":[]}`,
+		},
+		{
+			name:           "Invalid price_buckets parameter",
+			queryParams:    "?price_buckets=0,abc",
+			setupMock:      func(m *mockAdsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid price_buckets parameter: must be comma-separated numbers"}`,
+		},
+		{
+			name:        "Service rejects bounds",
+			queryParams: "?price_buckets=100,10",
+			setupMock: func(m *mockAdsService) {
+				m.GetFacetsFunc = func(ctx context.Context, params *domain.ListAdsParams, priceBounds []int64) (*domain.AdFacets, error) {
+					return nil, fmt.Errorf("%w: price_buckets must be strictly ascending", services.ErrInvalidInput)
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid input: price_buckets must be strictly ascending"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAdsService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAdsHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodGet, "/ads/facets"+tt.queryParams, nil)
+			rr := httptest.NewRecorder()
+			handler.GetFacets(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
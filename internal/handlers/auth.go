@@ -4,18 +4,27 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 
 	"github.com/felix-kado/vk-test-task/internal/domain"
 	"github.com/felix-kado/vk-test-task/internal/dto"
+	"github.com/felix-kado/vk-test-task/internal/middleware"
 	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/go-chi/chi/v5"
 )
 
 // AuthService defines the interface for authentication-related operations.
 type AuthService interface {
-	Register(ctx context.Context, login, password string) (string, *domain.User, error)
-	Login(ctx context.Context, login, password string) (string, error)
+	Register(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, *domain.User, error)
+	Login(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error)
+	Logout(ctx context.Context, userID int64, accessToken, refreshToken string) error
+	LogoutAll(ctx context.Context, userID int64) error
+	PublicKeys() []domain.PublicKey
+	ListSessions(ctx context.Context, userID int64) ([]domain.Session, error)
+	RevokeSession(ctx context.Context, userID int64, sessionID string) error
 }
 
 // AuthHandler handles HTTP requests for authentication.
@@ -37,12 +46,12 @@ type RegistrationRequest struct {
 
 // Register godoc
 // @Summary Register a new user
-// @Description Creates a new user and returns their ID.
+// @Description Creates a new user and returns their ID together with an access token and a refresh token.
 // @Tags auth
 // @Accept  json
 // @Produce  json
 // @Param   input body RegistrationRequest true "Registration Info"
-// @Success 201 {object} dto.UserResponse
+// @Success 201 {object} dto.RegistrationResponse
 // @Failure 400 {object} map[string]string
 // @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -55,7 +64,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, user, err := h.service.Register(r.Context(), req.Login, req.Password)
+	tokens, user, err := h.service.Register(r.Context(), req.Login, req.Password, middleware.ClientInfo(r))
 	if err != nil {
 		if errors.Is(err, services.ErrInvalidInput) {
 			respondWithError(w, http.StatusBadRequest, err.Error())
@@ -71,7 +80,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := dto.ToUserResponse(user)
+	resp := dto.RegistrationResponse{UserResponse: dto.ToUserResponse(user), TokenResponse: dto.ToTokenResponse(tokens)}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -87,14 +96,15 @@ type LoginRequest struct {
 
 // Login godoc
 // @Summary Log in a user
-// @Description Authenticates a user and returns a JWT token.
+// @Description Authenticates a user and returns a JWT access token and a refresh token.
 // @Tags auth
 // @Accept  json
 // @Produce  json
 // @Param   input body LoginRequest true "Login Credentials"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} dto.TokenResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /login [post]
 // Login handles user login requests.
@@ -105,7 +115,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.service.Login(r.Context(), req.Login, req.Password)
+	tokens, err := h.service.Login(r.Context(), req.Login, req.Password, middleware.ClientInfo(r))
 	if err != nil {
 		if errors.Is(err, services.ErrInvalidInput) {
 			respondWithError(w, http.StatusBadRequest, err.Error())
@@ -115,14 +125,204 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusUnauthorized, "invalid login or password")
 			return
 		}
+		if errors.Is(err, services.ErrTooManyRequests) {
+			handleServiceError(w, r, h.log, err)
+			return
+		}
 		h.log.Error("failed to login", slog.String("error", err.Error()))
 		respondWithError(w, http.StatusInternalServerError, "an internal error occurred")
 		return
 	}
 
-	resp := map[string]string{"token": token}
+	resp := dto.ToTokenResponse(tokens)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode JSON response", slog.String("error", err.Error()))
+	}
+}
+
+// RefreshRequest defines the structure for a token refresh request.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchanges a refresh token for a new access token and refresh token. A refresh token can be used once; reusing one revokes every token issued from the same login.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   input body RefreshRequest true "Refresh Token"
+// @Success 200 {object} dto.TokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/refresh [post]
+// Refresh handles token refresh requests.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	tokens, err := h.service.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrUnauthorized) {
+			respondWithError(w, http.StatusUnauthorized, "invalid refresh token")
+			return
+		}
+		h.log.Error("failed to refresh tokens", slog.String("error", err.Error()))
+		respondWithError(w, http.StatusInternalServerError, "an internal error occurred")
+		return
+	}
+
+	resp := dto.ToTokenResponse(tokens)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode JSON response", slog.String("error", err.Error()))
+	}
+}
+
+// LogoutRequest defines the structure for a logout request. The body is optional.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Logout godoc
+// @Summary Log out
+// @Security ApiKeyAuth
+// @Description Revokes the access token of the request together with its session and refresh token. For tokens issued before sessions, pass the refresh token to revoke it too.
+// @Tags auth
+// @Accept  json
+// @Param   input body LogoutRequest false "Refresh Token"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/logout [post]
+// Logout handles logout requests.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	accessToken, ok := middleware.BearerToken(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.service.Logout(r.Context(), userID, accessToken, req.RefreshToken); err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll godoc
+// @Summary Log out of all sessions
+// @Security ApiKeyAuth
+// @Description Revokes every access token and refresh token of the current user, on all devices.
+// @Tags auth
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/logout-all [post]
+// LogoutAll handles requests to log out of all sessions.
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.service.LogoutAll(r.Context(), userID); err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JWKS serves the public keys access tokens can be verified with as a JSON Web Key
+// Set. It lives at /.well-known/jwks.json, outside of the versioned API.
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	resp := dto.ToJWKSResponse(h.service.PublicKeys())
 	w.Header().Set("Content-Type", "application/json")
+	// Verifiers may cache the keys for a while; a new key is published before it signs tokens
+	w.Header().Set("Cache-Control", "public, max-age=300")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		h.log.Error("failed to encode JSON response", slog.String("error", err.Error()))
 	}
 }
+
+// ListSessions godoc
+// @Summary List sessions
+// @Security ApiKeyAuth
+// @Description Returns the devices the current user is logged in on, most recently used first.
+// @Tags auth
+// @Produce  json
+// @Success 200 {array} dto.SessionResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /me/sessions [get]
+// ListSessions handles requests for the sessions of the current user.
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	sessions, err := h.service.ListSessions(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	resp := dto.ToSessionResponseList(sessions)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode JSON response", slog.String("error", err.Error()))
+	}
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Security ApiKeyAuth
+// @Description Logs the current user out on one device.
+// @Tags auth
+// @Param   id path string true "Session ID"
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /me/sessions/{id} [delete]
+// RevokeSession handles session revocation requests.
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.service.RevokeSession(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -3,32 +3,81 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/middleware"
 	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 )
 
 // mockAuthService is a mock implementation of AuthService for testing.
 type mockAuthService struct {
-	RegisterFunc func(ctx context.Context, login, password string) (string, *domain.User, error)
-	LoginFunc    func(ctx context.Context, login, password string) (string, error)
+	RegisterFunc func(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, *domain.User, error)
+	LoginFunc    func(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, error)
+	RefreshFunc  func(ctx context.Context, refreshToken string) (*domain.TokenPair, error)
+
+	LogoutFunc     func(ctx context.Context, userID int64, accessToken, refreshToken string) error
+	LogoutAllFunc  func(ctx context.Context, userID int64) error
+	PublicKeysList []domain.PublicKey
+
+	ListSessionsFunc  func(ctx context.Context, userID int64) ([]domain.Session, error)
+	RevokeSessionFunc func(ctx context.Context, userID int64, sessionID string) error
+}
+
+func (m *mockAuthService) Register(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, *domain.User, error) {
+	return m.RegisterFunc(ctx, login, password, client)
+}
+
+func (m *mockAuthService) Login(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, error) {
+	return m.LoginFunc(ctx, login, password, client)
+}
+
+func (m *mockAuthService) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	return m.RefreshFunc(ctx, refreshToken)
+}
+
+func (m *mockAuthService) Logout(ctx context.Context, userID int64, accessToken, refreshToken string) error {
+	return m.LogoutFunc(ctx, userID, accessToken, refreshToken)
+}
+
+func (m *mockAuthService) LogoutAll(ctx context.Context, userID int64) error {
+	return m.LogoutAllFunc(ctx, userID)
+}
+
+func (m *mockAuthService) PublicKeys() []domain.PublicKey {
+	return m.PublicKeysList
 }
 
-func (m *mockAuthService) Register(ctx context.Context, login, password string) (string, *domain.User, error) {
-	return m.RegisterFunc(ctx, login, password)
+func (m *mockAuthService) ListSessions(ctx context.Context, userID int64) ([]domain.Session, error) {
+	return m.ListSessionsFunc(ctx, userID)
 }
 
-func (m *mockAuthService) Login(ctx context.Context, login, password string) (string, error) {
-	return m.LoginFunc(ctx, login, password)
+func (m *mockAuthService) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	return m.RevokeSessionFunc(ctx, userID, sessionID)
 }
 
+// testTokens is the token pair returned by the mock auth service.
+var testTokens = &domain.TokenPair{
+	AccessToken:      "token",
+	AccessExpiresAt:  time.Date(2025, 1, 1, 12, 15, 0, 0, time.UTC),
+	RefreshToken:     "refresh",
+	RefreshExpiresAt: time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC),
+}
+
+// testTokensJSON is testTokens as the handlers render them.
+const testTokensJSON = `"token":"token","access_token":"token","access_expires_at":"2025-01-01T12:15:00Z","refresh_token":"refresh","refresh_expires_at":"2025-01-31T12:00:00Z"`
+
 func TestAuthHandler_Register(t *testing.T) {
 	type errorResponse struct {
 		Error string `json:"error"`
@@ -48,12 +97,12 @@ func TestAuthHandler_Register(t *testing.T) {
 				"password": "ValidPass123!",
 			},
 			setupMock: func(m *mockAuthService) {
-				m.RegisterFunc = func(ctx context.Context, login, password string) (string, *domain.User, error) {
-					return "token", &domain.User{ID: 1, Login: login}, nil
+				m.RegisterFunc = func(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, *domain.User, error) {
+					return testTokens, &domain.User{ID: 1, Login: login}, nil
 				}
 			},
 			expectedStatus: http.StatusCreated,
-			expectedBody:   `{"id":1,"login":"testuser","created_at":"0001-01-01T00:00:00Z"}`,
+			expectedBody:   `{"id":1,"login":"testuser","created_at":"0001-01-01T00:00:00Z",` + testTokensJSON + `}`,
 		},
 		{
 			name: "validation error from service",
@@ -62,8 +111,8 @@ func TestAuthHandler_Register(t *testing.T) {
 				"password": "short",
 			},
 			setupMock: func(m *mockAuthService) {
-				m.RegisterFunc = func(ctx context.Context, login, password string) (string, *domain.User, error) {
-					return "", nil, fmt.Errorf("%w: invalid password", services.ErrInvalidInput)
+				m.RegisterFunc = func(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, *domain.User, error) {
+					return nil, nil, fmt.Errorf("%w: invalid password", services.ErrInvalidInput)
 				}
 			},
 			expectedStatus: http.StatusBadRequest,
@@ -76,8 +125,8 @@ func TestAuthHandler_Register(t *testing.T) {
 				"password": "ValidPass123!",
 			},
 			setupMock: func(m *mockAuthService) {
-				m.RegisterFunc = func(ctx context.Context, login, password string) (string, *domain.User, error) {
-					return "", nil, services.ErrUserExists
+				m.RegisterFunc = func(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, *domain.User, error) {
+					return nil, nil, services.ErrUserExists
 				}
 			},
 			expectedStatus: http.StatusConflict,
@@ -125,6 +174,7 @@ func TestAuthHandler_Login(t *testing.T) {
 		setupMock      func(*mockAuthService)
 		expectedStatus int
 		expectedBody   string
+		retryAfter     string
 	}{
 		{
 			name: "successful login",
@@ -133,12 +183,13 @@ func TestAuthHandler_Login(t *testing.T) {
 				"password": "ValidPass123!",
 			},
 			setupMock: func(m *mockAuthService) {
-				m.LoginFunc = func(ctx context.Context, login, password string) (string, error) {
-					return "token", nil
+				m.LoginFunc = func(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, error) {
+					assert.Equal(t, domain.ClientInfo{UserAgent: "test-agent", IP: "192.0.2.1"}, client)
+					return testTokens, nil
 				}
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"token":"token"}`,
+			expectedBody:   `{` + testTokensJSON + `}`,
 		},
 		{
 			name: "validation error from service",
@@ -147,8 +198,8 @@ func TestAuthHandler_Login(t *testing.T) {
 				"password": "ValidPass123!",
 			},
 			setupMock: func(m *mockAuthService) {
-				m.LoginFunc = func(ctx context.Context, login, password string) (string, error) {
-					return "", fmt.Errorf("%w: invalid login", services.ErrInvalidInput)
+				m.LoginFunc = func(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, error) {
+					return nil, fmt.Errorf("%w: invalid login", services.ErrInvalidInput)
 				}
 			},
 			expectedStatus: http.StatusBadRequest,
@@ -161,13 +212,28 @@ func TestAuthHandler_Login(t *testing.T) {
 				"password": "WrongPass123!",
 			},
 			setupMock: func(m *mockAuthService) {
-				m.LoginFunc = func(ctx context.Context, login, password string) (string, error) {
-					return "", services.ErrInvalidCredentials
+				m.LoginFunc = func(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, error) {
+					return nil, services.ErrInvalidCredentials
 				}
 			},
 			expectedStatus: http.StatusUnauthorized,
 			expectedBody:   "invalid login or password",
 		},
+		{
+			name: "locked out",
+			request: map[string]string{
+				"login":    "testuser",
+				"password": "ValidPass123!",
+			},
+			setupMock: func(m *mockAuthService) {
+				m.LoginFunc = func(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, error) {
+					return nil, &services.RetryAfterError{Err: services.ErrTooManyRequests, After: 90*time.Second + time.Millisecond}
+				}
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody:   "too many requests",
+			retryAfter:     "91",
+		},
 	}
 
 	for _, tt := range tests {
@@ -180,11 +246,13 @@ func TestAuthHandler_Login(t *testing.T) {
 			body, _ := json.Marshal(tt.request)
 			req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", "test-agent")
 
 			rr := httptest.NewRecorder()
 			handler.Login(rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.retryAfter, rr.Header().Get("Retry-After"))
 
 			if rr.Code >= 400 {
 				assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
@@ -198,3 +266,321 @@ func TestAuthHandler_Login(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	type errorResponse struct {
+		Error string `json:"error"`
+	}
+
+	tests := []struct {
+		name           string
+		request        map[string]string
+		setupMock      func(*mockAuthService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:    "successful refresh",
+			request: map[string]string{"refresh_token": "old"},
+			setupMock: func(m *mockAuthService) {
+				m.RefreshFunc = func(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+					assert.Equal(t, "old", refreshToken)
+					return testTokens, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{` + testTokensJSON + `}`,
+		},
+		{
+			name:    "missing token",
+			request: map[string]string{},
+			setupMock: func(m *mockAuthService) {
+				m.RefreshFunc = func(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+					return nil, fmt.Errorf("%w: refresh_token is required", services.ErrInvalidInput)
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid input: refresh_token is required",
+		},
+		{
+			name:    "invalid or reused token",
+			request: map[string]string{"refresh_token": "used"},
+			setupMock: func(m *mockAuthService) {
+				m.RefreshFunc = func(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+					return nil, services.ErrUnauthorized
+				}
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "invalid refresh token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAuthService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAuthHandler(mockSvc, slog.Default())
+
+			body, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			handler.Refresh(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if rr.Code >= 400 {
+				var errResp errorResponse
+				err := json.Unmarshal(rr.Body.Bytes(), &errResp)
+				assert.NoError(t, err, "failed to unmarshal error response")
+				assert.Equal(t, tt.expectedBody, errResp.Error)
+			} else {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Logout(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         int64
+		authHeader     string
+		body           string
+		setupMock      func(*mockAuthService)
+		expectedStatus int
+	}{
+		{
+			name:       "logout with refresh token",
+			userID:     1,
+			authHeader: "Bearer access",
+			body:       `{"refresh_token":"refresh"}`,
+			setupMock: func(m *mockAuthService) {
+				m.LogoutFunc = func(ctx context.Context, userID int64, accessToken, refreshToken string) error {
+					assert.Equal(t, int64(1), userID)
+					assert.Equal(t, "access", accessToken)
+					assert.Equal(t, "refresh", refreshToken)
+					return nil
+				}
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:       "logout without body",
+			userID:     1,
+			authHeader: "Bearer access",
+			setupMock: func(m *mockAuthService) {
+				m.LogoutFunc = func(ctx context.Context, userID int64, accessToken, refreshToken string) error {
+					assert.Empty(t, refreshToken)
+					return nil
+				}
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "invalid body",
+			userID:         1,
+			authHeader:     "Bearer access",
+			body:           `{`,
+			setupMock:      func(m *mockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unauthenticated",
+			setupMock:      func(m *mockAuthService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAuthService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewAuthHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/logout", bytes.NewBufferString(tt.body))
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.Logout(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestAuthHandler_LogoutAll(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         int64
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", 1, nil, http.StatusNoContent},
+		{"user not found", 1, services.ErrUserNotFound, http.StatusNotFound},
+		{"unauthenticated", 0, nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAuthService{
+				LogoutAllFunc: func(ctx context.Context, userID int64) error {
+					assert.Equal(t, tt.userID, userID)
+					return tt.serviceErr
+				},
+			}
+			handler := NewAuthHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/logout-all", nil)
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.LogoutAll(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestAuthHandler_JWKS(t *testing.T) {
+	tests := []struct {
+		name         string
+		keys         []domain.PublicKey
+		expectedBody string
+	}{
+		{
+			name: "RSA and Ed25519 keys",
+			keys: []domain.PublicKey{
+				{ID: "rsa", Algorithm: "RS256", Key: &rsa.PublicKey{N: big.NewInt(0xabcdef), E: 65537}},
+				{ID: "ed", Algorithm: "EdDSA", Key: ed25519.PublicKey{1, 2, 3}},
+			},
+			expectedBody: `{"keys":[
+				{"kty":"RSA","kid":"rsa","use":"sig","alg":"RS256","n":"q83v","e":"AQAB"},
+				{"kty":"OKP","kid":"ed","use":"sig","alg":"EdDSA","crv":"Ed25519","x":"AQID"}
+			]}`,
+		},
+		{
+			name:         "no asymmetric keys",
+			expectedBody: `{"keys":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAuthHandler(&mockAuthService{PublicKeysList: tt.keys}, slog.Default())
+
+			req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			rr := httptest.NewRecorder()
+			handler.JWKS(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestAuthHandler_ListSessions(t *testing.T) {
+	lastSeen := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		userID         int64
+		sessions       []domain.Session
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "lists sessions",
+			userID: 1,
+			sessions: []domain.Session{
+				{ID: "a", UserID: 1, UserAgent: "curl/8.0", IP: "192.0.2.1", CreatedAt: lastSeen.Add(-time.Hour), LastSeenAt: lastSeen},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":"a","user_agent":"curl/8.0","ip":"192.0.2.1","created_at":"2025-01-02T09:00:00Z","last_seen_at":"2025-01-02T10:00:00Z"}]`,
+		},
+		{
+			name:           "no sessions",
+			userID:         1,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:           "unauthenticated",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAuthService{
+				ListSessionsFunc: func(ctx context.Context, userID int64) ([]domain.Session, error) {
+					assert.Equal(t, tt.userID, userID)
+					return tt.sessions, nil
+				},
+			}
+			handler := NewAuthHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodGet, "/me/sessions", nil)
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ListSessions(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_RevokeSession(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         int64
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"revoked", 1, nil, http.StatusNoContent},
+		{"not found", 1, services.ErrSessionNotFound, http.StatusNotFound},
+		{"unauthenticated", 0, nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockAuthService{
+				RevokeSessionFunc: func(ctx context.Context, userID int64, sessionID string) error {
+					assert.Equal(t, tt.userID, userID)
+					assert.Equal(t, "a", sessionID)
+					return tt.serviceErr
+				},
+			}
+			handler := NewAuthHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodDelete, "/me/sessions/a", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "a")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.RevokeSession(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
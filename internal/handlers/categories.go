@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/dto"
+)
+
+// CategoriesService defines the interface for category-related operations.
+type CategoriesService interface {
+	GetTree(ctx context.Context) ([]*domain.Category, error)
+}
+
+// CategoriesHandler handles HTTP requests for categories.
+type CategoriesHandler struct {
+	service CategoriesService
+	log     *slog.Logger
+}
+
+// NewCategoriesHandler creates a new CategoriesHandler.
+func NewCategoriesHandler(service CategoriesService, log *slog.Logger) *CategoriesHandler {
+	return &CategoriesHandler{service: service, log: log}
+}
+
+// ListCategories godoc
+// @Summary List categories
+// @Description Returns the category tree.
+// @Tags categories
+// @Produce  json
+// @Success 200 {array} dto.CategoryResponse
+// @Failure 500 {object} map[string]string
+// @Router /categories [get]
+// ListCategories handles requests for the category tree.
+func (h *CategoriesHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.service.GetTree(r.Context())
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	resp := dto.ToCategoryTree(tree)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
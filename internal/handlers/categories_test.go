@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockCategoriesService is a mock implementation of CategoriesService for testing.
+type mockCategoriesService struct {
+	GetTreeFunc func(ctx context.Context) ([]*domain.Category, error)
+}
+
+func (m *mockCategoriesService) GetTree(ctx context.Context) ([]*domain.Category, error) {
+	return m.GetTreeFunc(ctx)
+}
+
+func TestCategoriesHandler_ListCategories(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMock      func(*mockCategoriesService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Success",
+			setupMock: func(m *mockCategoriesService) {
+				m.GetTreeFunc = func(ctx context.Context) ([]*domain.Category, error) {
+					return []*domain.Category{
+						{ID: 1, Name: "Transport", Slug: "transport", Children: []*domain.Category{
+							{ID: 2, Name: "Cars", Slug: "cars"},
+						}},
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":1,"name":"Transport","slug":"transport","children":[{"id":2,"name":"Cars","slug":"cars"}]}]`,
+		},
+		{
+			name: "Service error",
+			setupMock: func(m *mockCategoriesService) {
+				m.GetTreeFunc = func(ctx context.Context) ([]*domain.Category, error) {
+					return nil, errors.New("db error")
+				}
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockCategoriesService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewCategoriesHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodGet, "/categories", nil)
+			rr := httptest.NewRecorder()
+			handler.ListCategories(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
@@ -3,20 +3,36 @@ package handlers
 import (
 	"errors"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/felix-kado/vk-test-task/internal/services"
 )
 
 // handleServiceError maps service layer errors to HTTP responses.
 func handleServiceError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	var retry *services.RetryAfterError
+	if errors.As(err, &retry) {
+		// Retry-After is in whole seconds; round up so that a retry is not too early
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.After.Seconds()))))
+	}
+
 	switch {
 	case errors.Is(err, services.ErrInvalidInput):
 		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrPayloadTooLarge):
+		respondWithError(w, http.StatusRequestEntityTooLarge, err.Error())
 	case errors.Is(err, services.ErrConflict):
 		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrTooManyRequests):
+		respondWithError(w, http.StatusTooManyRequests, err.Error())
 	case errors.Is(err, services.ErrAdNotFound):
 		respondWithError(w, http.StatusNotFound, "ad not found")
+	case errors.Is(err, services.ErrImageNotFound):
+		respondWithError(w, http.StatusNotFound, "image not found")
+	case errors.Is(err, services.ErrSessionNotFound):
+		respondWithError(w, http.StatusNotFound, "session not found")
 	case errors.Is(err, services.ErrUserNotFound):
 		respondWithError(w, http.StatusNotFound, "user not found")
 	case errors.Is(err, services.ErrUnauthorized):
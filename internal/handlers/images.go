@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/dto"
+	"github.com/felix-kado/vk-test-task/internal/middleware"
+)
+
+const (
+	// imageFormField is the multipart field holding the uploaded image.
+	imageFormField = "file"
+
+	// maxUploadRequestSize caps the whole multipart request; the image size
+	// itself is limited by the service.
+	maxUploadRequestSize = 32 << 20
+)
+
+// ImagesService defines the interface for image-related operations.
+type ImagesService interface {
+	Upload(ctx context.Context, userID int64, r io.Reader) (*domain.UploadedImage, error)
+}
+
+// ImagesHandler handles HTTP requests for images.
+type ImagesHandler struct {
+	service ImagesService
+	log     *slog.Logger
+}
+
+// NewImagesHandler creates a new ImagesHandler.
+func NewImagesHandler(service ImagesService, log *slog.Logger) *ImagesHandler {
+	return &ImagesHandler{service: service, log: log}
+}
+
+// UploadImage godoc
+// @Summary Upload an image
+// @Security ApiKeyAuth
+// @Description Uploads a JPEG, PNG or WebP image and returns its URL for use as the image_url of an ad.
+// @Description The format is detected from the file content; size and dimensions are limited.
+// @Tags images
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   file formData file true "Image file"
+// @Success 201 {object} dto.ImageResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /images [post]
+// UploadImage handles image upload requests.
+func (h *ImagesHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadRequestSize)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "request must be multipart/form-data")
+		return
+	}
+
+	// Stream the file part to the service instead of buffering the form to disk
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			respondWithError(w, http.StatusBadRequest, "file field is required")
+			return
+		}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respondWithError(w, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			respondWithError(w, http.StatusBadRequest, "invalid multipart body")
+			return
+		}
+		if part.FormName() != imageFormField {
+			continue
+		}
+
+		img, err := h.service.Upload(r.Context(), userID, part)
+		if err != nil {
+			handleServiceError(w, r, h.log, err)
+			return
+		}
+
+		resp := dto.ToImageResponse(img)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			h.log.Error("failed to encode response", slog.String("error", err.Error()))
+		}
+		return
+	}
+}
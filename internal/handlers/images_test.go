@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/middleware"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockImagesService is a mock implementation of ImagesService for testing.
+type mockImagesService struct {
+	UploadFunc func(ctx context.Context, userID int64, r io.Reader) (*domain.UploadedImage, error)
+}
+
+func (m *mockImagesService) Upload(ctx context.Context, userID int64, r io.Reader) (*domain.UploadedImage, error) {
+	return m.UploadFunc(ctx, userID, r)
+}
+
+// multipartBody builds a multipart form with the given fields; a field named "file" is sent as a file.
+func multipartBody(fields map[string]string) (*bytes.Buffer, string) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for name, value := range fields {
+		if name == "file" {
+			fw, _ := mw.CreateFormFile(name, "photo.png")
+			_, _ = fw.Write([]byte(value))
+		} else {
+			_ = mw.WriteField(name, value)
+		}
+	}
+	_ = mw.Close()
+	return &buf, mw.FormDataContentType()
+}
+
+func TestImagesHandler_UploadImage(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         int64
+		fields         map[string]string
+		contentType    string
+		setupMock      func(*mockImagesService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Success",
+			userID: 1,
+			fields: map[string]string{"caption": "ignored", "file": "png bytes"},
+			setupMock: func(m *mockImagesService) {
+				m.UploadFunc = func(ctx context.Context, userID int64, r io.Reader) (*domain.UploadedImage, error) {
+					assert.Equal(t, int64(1), userID)
+					data, _ := io.ReadAll(r)
+					assert.Equal(t, "png bytes", string(data))
+					return &domain.UploadedImage{
+						Key: "2026/10/16/abc.png", URL: "http://localhost:8080/media/2026/10/16/abc.png",
+						ContentType: "image/png", Size: 9, Width: 40, Height: 30,
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"url":"http://localhost:8080/media/2026/10/16/abc.png","content_type":"image/png","size":9,"width":40,"height":30}`,
+		},
+		{
+			name:           "Unauthorized",
+			fields:         map[string]string{"file": "png bytes"},
+			setupMock:      func(m *mockImagesService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"unauthorized"}`,
+		},
+		{
+			name:           "Missing file field",
+			userID:         1,
+			fields:         map[string]string{"caption": "no file"},
+			setupMock:      func(m *mockImagesService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"file field is required"}`,
+		},
+		{
+			name:           "Not multipart",
+			userID:         1,
+			contentType:    "application/json",
+			setupMock:      func(m *mockImagesService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"request must be multipart/form-data"}`,
+		},
+		{
+			name:   "Image too large",
+			userID: 1,
+			fields: map[string]string{"file": "huge"},
+			setupMock: func(m *mockImagesService) {
+				m.UploadFunc = func(ctx context.Context, userID int64, r io.Reader) (*domain.UploadedImage, error) {
+					return nil, fmt.Errorf("%w: image cannot exceed 5242880 bytes", services.ErrPayloadTooLarge)
+				}
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `{"error":"payload too large: image cannot exceed 5242880 bytes"}`,
+		},
+		{
+			name:   "Request too large",
+			userID: 1,
+			fields: map[string]string{"file": strings.Repeat("x", maxUploadRequestSize)},
+			setupMock: func(m *mockImagesService) {
+				m.UploadFunc = func(ctx context.Context, userID int64, r io.Reader) (*domain.UploadedImage, error) {
+					// The request limit applies to the file part read by the service
+					_, err := io.ReadAll(r)
+					var maxBytesErr *http.MaxBytesError
+					assert.ErrorAs(t, err, &maxBytesErr)
+					return nil, fmt.Errorf("%w: request cannot exceed %d bytes", services.ErrPayloadTooLarge, maxBytesErr.Limit)
+				}
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `{"error":"payload too large: request cannot exceed 33554432 bytes"}`,
+		},
+		{
+			name:   "Unsupported format",
+			userID: 1,
+			fields: map[string]string{"file": "GIF89a"},
+			setupMock: func(m *mockImagesService) {
+				m.UploadFunc = func(ctx context.Context, userID int64, r io.Reader) (*domain.UploadedImage, error) {
+					return nil, fmt.Errorf("%w: image must be a JPEG, PNG or WebP file", services.ErrInvalidInput)
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid input: image must be a JPEG, PNG or WebP file"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockImagesService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewImagesHandler(mockSvc, slog.Default())
+
+			body, contentType := multipartBody(tt.fields)
+			if tt.contentType != "" {
+				contentType = tt.contentType
+			}
+			req := httptest.NewRequest(http.MethodPost, "/images", body)
+			req.Header.Set("Content-Type", contentType)
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.UploadImage(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
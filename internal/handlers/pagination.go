@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/felix-kado/vk-test-task/internal/dto"
+)
+
+// adsEnvelopeMediaType is the Accept value that opts into the paginated
+// envelope for the ad feed. Clients that do not send it keep getting a bare array.
+const adsEnvelopeMediaType = "application/vnd.marketplace.v2+json"
+
+// acceptsAdsEnvelope reports whether the client asked for the paginated envelope.
+func acceptsAdsEnvelope(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), adsEnvelopeMediaType)
+}
+
+// adsLinkHeader builds an RFC 8288 Link header value with first/prev/next/last
+// relations for page pagination, or just next for keyset pagination.
+func adsLinkHeader(r *http.Request, resp *dto.AdListResponse, cursorMode bool) string {
+	var links []string
+
+	if cursorMode {
+		if resp.NextCursor != "" {
+			links = append(links, pageLink(r, "cursor", resp.NextCursor, "next"))
+		}
+		return strings.Join(links, ", ")
+	}
+
+	lastPage := 1
+	if resp.Limit > 0 && resp.Total > 0 {
+		lastPage = int((resp.Total + int64(resp.Limit) - 1) / int64(resp.Limit))
+	}
+
+	links = append(links, pageLink(r, "page", "1", "first"))
+	if resp.Page > 1 {
+		links = append(links, pageLink(r, "page", strconv.Itoa(resp.Page-1), "prev"))
+	}
+	if resp.HasNext {
+		links = append(links, pageLink(r, "page", strconv.Itoa(resp.Page+1), "next"))
+	}
+	links = append(links, pageLink(r, "page", strconv.Itoa(lastPage), "last"))
+
+	return strings.Join(links, ", ")
+}
+
+// pageLink returns a single Link entry pointing at the current request URL
+// with the given query parameter replaced.
+func pageLink(r *http.Request, key, value, rel string) string {
+	query := r.URL.Query()
+	query.Set(key, value)
+	return fmt.Sprintf(`<%s?%s>; rel="%s"`, r.URL.Path, query.Encode(), rel)
+}
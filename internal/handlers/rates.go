@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/dto"
+	"github.com/felix-kado/vk-test-task/internal/middleware"
+)
+
+// RatesService defines the interface for exchange rate operations.
+type RatesService interface {
+	ListRates(ctx context.Context) ([]domain.ExchangeRate, error)
+	SetRates(ctx context.Context, userID int64, rates map[domain.Currency]float64) ([]domain.ExchangeRate, error)
+}
+
+// RatesHandler handles HTTP requests for exchange rates.
+type RatesHandler struct {
+	service RatesService
+	log     *slog.Logger
+}
+
+// NewRatesHandler creates a new RatesHandler.
+func NewRatesHandler(service RatesService, log *slog.Logger) *RatesHandler {
+	return &RatesHandler{service: service, log: log}
+}
+
+// SetRatesRequest defines the exchange rates to create or replace.
+type SetRatesRequest struct {
+	Rates map[string]float64 `json:"rates"` // currency code to the price of one unit in RUB, e.g. {"KZT": 0.19}
+}
+
+// ListRates godoc
+// @Summary List exchange rates
+// @Description Returns the exchange rates used to convert ad prices, quoted in RUB.
+// @Tags rates
+// @Produce  json
+// @Success 200 {array} dto.ExchangeRateResponse
+// @Failure 500 {object} map[string]string
+// @Router /exchange-rates [get]
+// ListRates handles requests for the exchange rates.
+func (h *RatesHandler) ListRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.ListRates(r.Context())
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	h.respondWithRates(w, rates)
+}
+
+// SetRates godoc
+// @Summary Update exchange rates
+// @Security ApiKeyAuth
+// @Description Creates or replaces the exchange rates of the given currencies and returns all rates. Administrators only.
+// @Tags rates
+// @Accept  json
+// @Produce  json
+// @Param   input body SetRatesRequest true "Exchange rates"
+// @Success 200 {array} dto.ExchangeRateResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /exchange-rates [put]
+// SetRates handles exchange rate updates.
+func (h *RatesHandler) SetRates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req SetRatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rates := make(map[domain.Currency]float64, len(req.Rates))
+	for currency, rate := range req.Rates {
+		rates[domain.Currency(strings.ToUpper(currency))] = rate
+	}
+
+	updated, err := h.service.SetRates(r.Context(), userID, rates)
+	if err != nil {
+		handleServiceError(w, r, h.log, err)
+		return
+	}
+
+	h.respondWithRates(w, updated)
+}
+
+func (h *RatesHandler) respondWithRates(w http.ResponseWriter, rates []domain.ExchangeRate) {
+	resp := dto.ToExchangeRateResponseList(rates)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
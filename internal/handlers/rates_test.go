@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/middleware"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockRatesService is a mock implementation of RatesService for testing.
+type mockRatesService struct {
+	ListRatesFunc func(ctx context.Context) ([]domain.ExchangeRate, error)
+	SetRatesFunc  func(ctx context.Context, userID int64, rates map[domain.Currency]float64) ([]domain.ExchangeRate, error)
+}
+
+func (m *mockRatesService) ListRates(ctx context.Context) ([]domain.ExchangeRate, error) {
+	return m.ListRatesFunc(ctx)
+}
+
+func (m *mockRatesService) SetRates(ctx context.Context, userID int64, rates map[domain.Currency]float64) ([]domain.ExchangeRate, error) {
+	return m.SetRatesFunc(ctx, userID, rates)
+}
+
+func TestRatesHandler_SetRates(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		userID         int64
+		body           string
+		setupMock      func(*mockRatesService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Success",
+			userID: 1,
+			body:   `{"rates":{"kzt":0.19}}`,
+			setupMock: func(m *mockRatesService) {
+				m.SetRatesFunc = func(ctx context.Context, userID int64, rates map[domain.Currency]float64) ([]domain.ExchangeRate, error) {
+					assert.Equal(t, int64(1), userID)
+					assert.Equal(t, map[domain.Currency]float64{domain.CurrencyKZT: 0.19}, rates)
+					return []domain.ExchangeRate{
+						{Currency: domain.CurrencyKZT, Rate: 0.19, UpdatedAt: updatedAt},
+						{Currency: domain.CurrencyRUB, Rate: 1, UpdatedAt: updatedAt},
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"currency":"KZT","rate":0.19,"updated_at":"2026-10-16T12:00:00Z"},{"currency":"RUB","rate":1,"updated_at":"2026-10-16T12:00:00Z"}]`,
+		},
+		{
+			name:   "Not an administrator",
+			userID: 2,
+			body:   `{"rates":{"KZT":0.19}}`,
+			setupMock: func(m *mockRatesService) {
+				m.SetRatesFunc = func(ctx context.Context, userID int64, rates map[domain.Currency]float64) ([]domain.ExchangeRate, error) {
+					return nil, services.ErrForbidden
+				}
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"forbidden"}`,
+		},
+		{
+			name:           "Invalid body",
+			userID:         1,
+			body:           `{"rates":{"KZT":"cheap"}}`,
+			setupMock:      func(m *mockRatesService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid request body"}`,
+		},
+		{
+			name:           "Unauthorized",
+			body:           `{"rates":{"KZT":0.19}}`,
+			setupMock:      func(m *mockRatesService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"unauthorized"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockRatesService{}
+			tt.setupMock(mockSvc)
+
+			handler := NewRatesHandler(mockSvc, slog.Default())
+
+			req := httptest.NewRequest(http.MethodPut, "/exchange-rates", bytes.NewBufferString(tt.body))
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.SetRates(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"log/slog"
 	"net/http"
+	"net/netip"
 
 	"github.com/felix-kado/vk-test-task/internal/middleware"
 	"github.com/go-chi/chi/v5"
@@ -10,33 +11,57 @@ import (
 )
 
 // NewRouter creates a new chi router and sets up the routes and middlewares.
-func NewRouter(log *slog.Logger, authHandler *AuthHandler, adsHandler *AdsHandler, authService middleware.AuthService) *chi.Mux {
+func NewRouter(log *slog.Logger, authHandler *AuthHandler, adsHandler *AdsHandler, categoriesHandler *CategoriesHandler, imagesHandler *ImagesHandler, ratesHandler *RatesHandler, authService middleware.AuthService, trustedProxies []netip.Prefix) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Base middlewares
 	r.Use(chimiddleware.RequestID)
+	r.Use(middleware.ClientIPCtx(trustedProxies))
 	r.Use(middleware.RequestLogger(log))
 	r.Use(chimiddleware.Recoverer)
-	r.Use(chimiddleware.URLFormat)
 
-	// Health check
-	r.Get("/v1/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	})
-
-	// Public routes
-	r.Post("/v1/register", authHandler.Register)
-	r.Post("/v1/login", authHandler.Login)
+	// The key set is served by its exact name, so it stays outside URLFormat,
+	// which would strip the .json extension before routing
+	r.Get("/.well-known/jwks.json", authHandler.JWKS)
 
+	r.Group(func(r chi.Router) {
+		r.Use(chimiddleware.URLFormat)
 
+		// Health check
+		r.Get("/v1/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
 
+		// Public routes
+		r.Post("/v1/register", authHandler.Register)
+		r.Post("/v1/login", authHandler.Login)
+		r.Post("/v1/auth/refresh", authHandler.Refresh)
+		r.Get("/v1/categories", categoriesHandler.ListCategories)
+		r.Get("/v1/exchange-rates", ratesHandler.ListRates)
 		r.With(middleware.AuthOptionalCtx(authService)).Get("/v1/ads", adsHandler.ListAds)
+		r.Get("/v1/ads/suggest", adsHandler.SuggestAds)
+		r.With(middleware.AuthOptionalCtx(authService)).Get("/v1/ads/facets", adsHandler.GetFacets)
+		r.With(middleware.AuthOptionalCtx(authService)).Get("/v1/ads/{id}", adsHandler.GetAd)
 
-	// Protected routes
-	r.Group(func(r chi.Router) {
-		r.Use(middleware.AuthCtx(authService))
-		r.Post("/v1/ads", adsHandler.CreateAd)
-		
+		// Protected routes
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.AuthCtx(authService))
+			r.Post("/v1/ads", adsHandler.CreateAd)
+			r.Patch("/v1/ads/{id}", adsHandler.UpdateAd)
+			r.Get("/v1/ads/{id}/history", adsHandler.GetAdHistory)
+			r.Delete("/v1/ads/{id}", adsHandler.DeleteAd)
+			r.Post("/v1/ads/{id}/status", adsHandler.ChangeAdStatus)
+			r.Post("/v1/ads/{id}/renew", adsHandler.RenewAd)
+			r.Post("/v1/ads/{id}/bump", adsHandler.BumpAd)
+			r.Put("/v1/ads/{id}/images/order", adsHandler.ReorderAdImages)
+			r.Delete("/v1/ads/{id}/images/{imageID}", adsHandler.RemoveAdImage)
+			r.Post("/v1/images", imagesHandler.UploadImage)
+			r.Put("/v1/exchange-rates", ratesHandler.SetRates)
+			r.Post("/v1/auth/logout", authHandler.Logout)
+			r.Post("/v1/auth/logout-all", authHandler.LogoutAll)
+			r.Get("/v1/me/sessions", authHandler.ListSessions)
+			r.Delete("/v1/me/sessions/{id}", authHandler.RevokeSession)
+		})
 	})
 
 	return r
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRouter_JWKS(t *testing.T) {
+	authHandler := NewAuthHandler(&mockAuthService{}, slog.Default())
+	router := NewRouter(slog.Default(), authHandler, NewAdsHandler(nil, slog.Default()), NewCategoriesHandler(nil, slog.Default()),
+		NewImagesHandler(nil, slog.Default()), NewRatesHandler(nil, slog.Default()), nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"keys":[]}`, rr.Body.String())
+}
+
+func TestNewRouter_SessionClientIP(t *testing.T) {
+	var clients []domain.ClientInfo
+	authService := &mockAuthService{
+		RegisterFunc: func(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, *domain.User, error) {
+			clients = append(clients, client)
+			return &domain.TokenPair{}, &domain.User{ID: 1, Login: login}, nil
+		},
+		LoginFunc: func(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, error) {
+			clients = append(clients, client)
+			return &domain.TokenPair{}, nil
+		},
+	}
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	router := NewRouter(slog.Default(), NewAuthHandler(authService, slog.Default()), NewAdsHandler(nil, slog.Default()), NewCategoriesHandler(nil, slog.Default()),
+		NewImagesHandler(nil, slog.Default()), NewRatesHandler(nil, slog.Default()), nil, trusted)
+
+	// Sessions record the client behind the trusted proxy, not the proxy itself
+	for _, path := range []string{"/v1/register", "/v1/login"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"login":"testuser","password":"ValidPass123!"}`))
+		req.RemoteAddr = "10.0.0.5:5000"
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		req.Header.Set("User-Agent", "test-agent")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Less(t, rr.Code, 300, path)
+	}
+
+	assert.Equal(t, []domain.ClientInfo{
+		{UserAgent: "test-agent", IP: "203.0.113.9"},
+		{UserAgent: "test-agent", IP: "203.0.113.9"},
+	}, clients)
+}
@@ -0,0 +1,191 @@
+// Package images contains the infrastructure for ad images.
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// maxRedirects limits the number of redirects followed when fetching an image.
+const maxRedirects = 3
+
+// ErrForbiddenAddress is returned when an image URL resolves to a non-public address.
+var ErrForbiddenAddress = errors.New("address is not allowed")
+
+// nonPublicPrefixes are the special-purpose ranges from the IANA registries that are
+// not reachable on the public internet, or that tunnel to an address that may not be.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved and broadcast
+	netip.MustParsePrefix("::/96"),           // unspecified, loopback and IPv4-compatible
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/23"),       // IETF protocol assignments, including Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4
+	netip.MustParsePrefix("3fff::/20"),       // documentation
+	netip.MustParsePrefix("fc00::/7"),        // unique local
+	netip.MustParsePrefix("fe80::/10"),       // link-local
+	netip.MustParsePrefix("fec0::/10"),       // site-local
+	netip.MustParsePrefix("ff00::/8"),        // multicast
+}
+
+// isPublicAddr reports whether addr is outside all nonPublicPrefixes.
+// IPv4-mapped IPv6 addresses are checked as the IPv4 address they carry.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// HTTPFetcher reads image metadata over HTTP without downloading the image.
+type HTTPFetcher struct {
+	client *http.Client
+}
+
+// NewHTTPFetcher creates a fetcher that sends requests through client.
+func NewHTTPFetcher(client *http.Client) *HTTPFetcher {
+	return &HTTPFetcher{client: client}
+}
+
+// NewHTTPClient returns a client suitable for fetching user-supplied URLs: it has
+// an overall timeout, follows few redirects and refuses to connect to non-public
+// addresses (see isPublicAddr).
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !isPublicAddr(addr) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// FetchImageInfo returns the content type and size of the image at url.
+// It sends a HEAD request and falls back to a GET of the first byte for servers
+// that do not support HEAD or do not report the size in it.
+func (f *HTTPFetcher) FetchImageInfo(ctx context.Context, url string) (*domain.ImageInfo, error) {
+	info, err := f.head(ctx, url)
+	if err == nil && info.Size >= 0 {
+		return info, nil
+	}
+	return f.rangedGet(ctx, url)
+}
+
+func (f *HTTPFetcher) head(ctx context.Context, url string) (*domain.ImageInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return &domain.ImageInfo{ContentType: mediaType(resp.Header), Size: resp.ContentLength}, nil
+}
+
+func (f *HTTPFetcher) rangedGet(ctx context.Context, url string) (*domain.ImageInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", "bytes=0-0")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	// The body is never read, so a server ignoring Range costs nothing beyond the headers
+	defer resp.Body.Close()
+
+	info := &domain.ImageInfo{ContentType: mediaType(resp.Header), Size: -1}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		info.Size = contentRangeTotal(resp.Header.Get("Content-Range"))
+	case http.StatusOK:
+		info.Size = resp.ContentLength
+	default:
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return info, nil
+}
+
+// mediaType returns the media type of the Content-Type header without parameters.
+func mediaType(h http.Header) string {
+	mt, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return mt
+}
+
+// contentRangeTotal parses the complete length from a "bytes 0-0/12345" header,
+// returning -1 if it is missing or unknown.
+func contentRangeTotal(h string) int64 {
+	_, total, ok := strings.Cut(h, "/")
+	if !ok {
+		return -1
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
@@ -0,0 +1,145 @@
+package images
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPFetcher_FetchImageInfo(t *testing.T) {
+	tests := []struct {
+		name         string
+		handler      http.HandlerFunc
+		expectedInfo *domain.ImageInfo
+		expectErr    bool
+	}{
+		{
+			name: "HEAD reports type and size",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodHead, r.Method)
+				w.Header().Set("Content-Type", "image/jpeg")
+				w.Header().Set("Content-Length", "2048")
+			},
+			expectedInfo: &domain.ImageInfo{ContentType: "image/jpeg", Size: 2048},
+		},
+		{
+			name: "Falls back to ranged GET when HEAD is not allowed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				assert.Equal(t, "bytes=0-0", r.Header.Get("Range"))
+				w.Header().Set("Content-Type", "image/png; charset=binary")
+				w.Header().Set("Content-Range", "bytes 0-0/4096")
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write([]byte{0x89})
+			},
+			expectedInfo: &domain.ImageInfo{ContentType: "image/png", Size: 4096},
+		},
+		{
+			name: "Unknown size",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "image/webp")
+				w.Header().Set("Content-Range", "bytes 0-0/*")
+				w.WriteHeader(http.StatusPartialContent)
+			},
+			expectedInfo: &domain.ImageInfo{ContentType: "image/webp", Size: -1},
+		},
+		{
+			name: "Not found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			fetcher := NewHTTPFetcher(srv.Client())
+			info, err := fetcher.FetchImageInfo(context.Background(), srv.URL+"/image")
+
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedInfo, info)
+			}
+		})
+	}
+}
+
+func TestHTTPFetcher_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := NewHTTPFetcher(srv.Client()).FetchImageInfo(ctx, srv.URL)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNewHTTPClient_RefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a loopback server")
+	}))
+	defer srv.Close()
+
+	_, err := NewHTTPFetcher(NewHTTPClient(time.Second)).FetchImageInfo(context.Background(), srv.URL)
+
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr     string
+		expected bool
+	}{
+		{addr: "93.184.216.34", expected: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", expected: true},
+		{addr: "::ffff:93.184.216.34", expected: true},
+		{addr: "0.0.0.0", expected: false},
+		{addr: "127.0.0.1", expected: false},
+		{addr: "10.1.2.3", expected: false},
+		{addr: "172.16.0.1", expected: false},
+		{addr: "192.168.1.1", expected: false},
+		{addr: "169.254.169.254", expected: false},
+		{addr: "100.64.0.1", expected: false},
+		{addr: "100.127.255.254", expected: false},
+		{addr: "192.0.0.170", expected: false},
+		{addr: "198.18.0.1", expected: false},
+		{addr: "198.19.255.254", expected: false},
+		{addr: "224.0.0.1", expected: false},
+		{addr: "255.255.255.255", expected: false},
+		{addr: "::", expected: false},
+		{addr: "::1", expected: false},
+		{addr: "::ffff:127.0.0.1", expected: false},
+		{addr: "64:ff9b::a00:1", expected: false},
+		{addr: "64:ff9b::5db8:d822", expected: false},
+		{addr: "2002:a00:1::1", expected: false},
+		{addr: "2001:0:4136:e378:8000:63bf:3fff:fdd2", expected: false},
+		{addr: "fd00::1", expected: false},
+		{addr: "fe80::1%eth0", expected: false},
+		{addr: "ff02::1", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isPublicAddr(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
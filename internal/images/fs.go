@@ -0,0 +1,131 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// FSStore keeps blobs as files in a local directory.
+type FSStore struct {
+	dir     string
+	fsys    fs.FS
+	baseURL string
+}
+
+// NewFSStore creates a store in dir, creating the directory if needed.
+// baseURL is the public URL the directory is served at, see Handler.
+func NewFSStore(dir, baseURL string) (*FSStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("images.NewFSStore: %w", err)
+	}
+	return &FSStore{dir: dir, fsys: os.DirFS(dir), baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+// Put writes a blob atomically, so readers never see a partially written file.
+func (s *FSStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !validKey(key) {
+		return fmt.Errorf("images.FSStore.Put: invalid key %q", key)
+	}
+	name := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("images.FSStore.Put: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("images.FSStore.Put: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("images.FSStore.Put: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("images.FSStore.Put: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("images.FSStore.Put: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("images.FSStore.Put: %w", err)
+	}
+	return nil
+}
+
+// Get reads a blob.
+func (s *FSStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if !validKey(key) {
+		return nil, ErrBlobNotFound
+	}
+	data, err := fs.ReadFile(s.fsys, key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("images.FSStore.Get: %w", err)
+	}
+	return data, nil
+}
+
+// Stat returns the content type and size of a blob.
+func (s *FSStore) Stat(ctx context.Context, key string) (*domain.ImageInfo, error) {
+	if !validKey(key) {
+		return nil, ErrBlobNotFound
+	}
+	fi, err := fs.Stat(s.fsys, key)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && fi.IsDir()) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("images.FSStore.Stat: %w", err)
+	}
+	return &domain.ImageInfo{ContentType: typeByKey(key), Size: fi.Size()}, nil
+}
+
+// URL returns the public URL of a blob.
+func (s *FSStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// Handler serves the blobs by key. Directory listings are not served.
+// Blobs are never overwritten, so they may be cached indefinitely.
+func (s *FSStore) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		if !validKey(key) {
+			http.NotFound(w, r)
+			return
+		}
+		if fi, err := fs.Stat(s.fsys, key); err != nil || fi.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		http.ServeFileFS(w, r, s.fsys, key)
+	})
+}
+
+// validKey reports whether key is a relative path inside the store made of
+// URL-safe characters. Dot files are reserved for files being written.
+func validKey(key string) bool {
+	if !fs.ValidPath(key) || key == "." {
+		return false
+	}
+	for _, elem := range strings.Split(key, "/") {
+		if strings.HasPrefix(elem, ".") {
+			return false
+		}
+	}
+	return strings.Trim(key, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789._/-") == ""
+}
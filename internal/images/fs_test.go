@@ -0,0 +1,78 @@
+package images
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSStore(t *testing.T) {
+	store, err := NewFSStore(t.TempDir(), "http://localhost:8080/media/")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	err = store.Put(ctx, "2026/10/16/abc.jpg", []byte("jpeg data"), "image/jpeg")
+	assert.NoError(t, err)
+
+	info, err := store.Stat(ctx, "2026/10/16/abc.jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.ImageInfo{ContentType: "image/jpeg", Size: 9}, info)
+
+	data, err := store.Get(ctx, "2026/10/16/abc.jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("jpeg data"), data)
+
+	assert.Equal(t, "http://localhost:8080/media/2026/10/16/abc.jpg", store.URL("2026/10/16/abc.jpg"))
+
+	for _, key := range []string{"2026/10/16/missing.jpg", "2026/10/16", "../etc/passwd", "/etc/passwd", "2026/.upload-1"} {
+		_, err := store.Stat(ctx, key)
+		assert.ErrorIs(t, err, ErrBlobNotFound, key)
+	}
+	assert.Error(t, store.Put(ctx, "../escape.jpg", []byte("x"), "image/jpeg"))
+}
+
+func TestFSStore_Handler(t *testing.T) {
+	store, err := NewFSStore(t.TempDir(), "http://localhost:8080/media")
+	assert.NoError(t, err)
+	assert.NoError(t, store.Put(context.Background(), "2026/abc.png", []byte("png data"), "image/png"))
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{path: "/2026/abc.png", expectedStatus: http.StatusOK},
+		{path: "/2026/", expectedStatus: http.StatusNotFound},
+		{path: "/2026/missing.png", expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		store.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		assert.Equal(t, tt.expectedStatus, rr.Code, tt.path)
+		if tt.expectedStatus == http.StatusOK {
+			assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+			assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "png data", rr.Body.String())
+		}
+	}
+}
+
+func TestStoreFetcher(t *testing.T) {
+	store, err := NewFSStore(t.TempDir(), "http://localhost:8080/media")
+	assert.NoError(t, err)
+	assert.NoError(t, store.Put(context.Background(), "2026/abc.webp", []byte("webp"), "image/webp"))
+
+	// The remote fetcher refuses loopback addresses, so local uploads must be served from the store
+	fetcher := NewStoreFetcher(store, NewHTTPFetcher(NewHTTPClient(0)))
+
+	info, err := fetcher.FetchImageInfo(context.Background(), "http://localhost:8080/media/2026/abc.webp")
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.ImageInfo{ContentType: "image/webp", Size: 4}, info)
+
+	_, err = fetcher.FetchImageInfo(context.Background(), "http://localhost:8080/other/abc.webp")
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+}
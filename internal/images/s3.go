@@ -0,0 +1,210 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// S3Config configures an S3-compatible store such as AWS S3 or MinIO.
+type S3Config struct {
+	Endpoint  string // e.g. "https://s3.eu-central-1.amazonaws.com" or "http://minio:9000"
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	PublicURL string // base URL the bucket is publicly served at; defaults to Endpoint/Bucket
+}
+
+// S3Store keeps blobs in an S3-compatible bucket, addressed path-style.
+// Requests are signed with AWS Signature Version 4.
+type S3Store struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Store creates a store that sends requests through client.
+func NewS3Store(cfg S3Config, client *http.Client) *S3Store {
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = cfg.Endpoint + "/" + cfg.Bucket
+	}
+	cfg.PublicURL = strings.TrimSuffix(cfg.PublicURL, "/")
+	return &S3Store{cfg: cfg, client: client, now: time.Now}
+}
+
+// Put uploads a blob as a publicly cacheable object.
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !validKey(key) {
+		return fmt.Errorf("images.S3Store.Put: invalid key %q", key)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("images.S3Store.Put: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	s.sign(req, data)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("images.S3Store.Put: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("images.S3Store.Put: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Get downloads a blob.
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	if !validKey(key) {
+		return nil, ErrBlobNotFound
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("images.S3Store.Get: %w", err)
+	}
+	s.sign(req, nil)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("images.S3Store.Get: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("images.S3Store.Get: %w", err)
+		}
+		return data, nil
+	case http.StatusNotFound:
+		return nil, ErrBlobNotFound
+	default:
+		return nil, fmt.Errorf("images.S3Store.Get: unexpected status %d", resp.StatusCode)
+	}
+}
+
+// Stat returns the content type and size of a blob.
+func (s *S3Store) Stat(ctx context.Context, key string) (*domain.ImageInfo, error) {
+	if !validKey(key) {
+		return nil, ErrBlobNotFound
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.objectURL(key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("images.S3Store.Stat: %w", err)
+	}
+	s.sign(req, nil)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("images.S3Store.Stat: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return &domain.ImageInfo{ContentType: mediaType(resp.Header), Size: resp.ContentLength}, nil
+	case http.StatusNotFound:
+		return nil, ErrBlobNotFound
+	default:
+		return nil, fmt.Errorf("images.S3Store.Stat: unexpected status %d", resp.StatusCode)
+	}
+}
+
+// URL returns the public URL of a blob.
+func (s *S3Store) URL(key string) string {
+	return s.cfg.PublicURL + "/" + key
+}
+
+func (s *S3Store) objectURL(key string) string {
+	return s.cfg.Endpoint + "/" + s.cfg.Bucket + "/" + key
+}
+
+// sign adds AWS Signature Version 4 headers to req. The host, Content-Type,
+// Range and all x-amz-* headers are signed.
+func (s *S3Store) sign(req *http.Request, payload []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	payloadHash := sha256Hex(payload)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || name == "range" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+// canonicalPath returns the URI-encoded path as required by Signature Version 4 for S3.
+// Keys only contain unreserved characters, so only the bucket path may need escaping.
+func canonicalPath(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	return u.EscapedPath()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,51 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// ErrBlobNotFound is returned when a blob does not exist in the store.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// Store is a blob store for uploaded images. Keys are slash-separated relative paths.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Stat(ctx context.Context, key string) (*domain.ImageInfo, error)
+	URL(key string) string
+}
+
+// StoreFetcher reads the metadata of images uploaded to a store directly from it,
+// and of any other image over HTTP.
+type StoreFetcher struct {
+	store  Store
+	remote *HTTPFetcher
+}
+
+// NewStoreFetcher creates a fetcher for images in store that falls back to remote.
+func NewStoreFetcher(store Store, remote *HTTPFetcher) *StoreFetcher {
+	return &StoreFetcher{store: store, remote: remote}
+}
+
+// FetchImageInfo returns the content type and size of the image at url.
+func (f *StoreFetcher) FetchImageInfo(ctx context.Context, url string) (*domain.ImageInfo, error) {
+	if key, ok := strings.CutPrefix(url, f.store.URL("")); ok {
+		return f.store.Stat(ctx, key)
+	}
+	return f.remote.FetchImageInfo(ctx, url)
+}
+
+// typeByKey returns the content type implied by the extension of a blob key.
+func typeByKey(key string) string {
+	mt, _, err := mime.ParseMediaType(mime.TypeByExtension(path.Ext(key)))
+	if err != nil {
+		return ""
+	}
+	return mt
+}
@@ -20,30 +20,32 @@ type AuthService interface {
 	ParseToken(ctx context.Context, token string) (*domain.User, error)
 }
 
+// BearerToken returns the token from the Authorization header of r, if there is one.
+func BearerToken(r *http.Request) (string, bool) {
+	headerParts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(headerParts) != 2 || headerParts[0] != "Bearer" {
+		return "", false
+	}
+	return headerParts[1], true
+}
+
 // AuthCtx is a middleware that extracts the JWT from the Authorization header
 // and sets the user information in the request context.
 func AuthOptionalCtx(authService AuthService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				next.ServeHTTP(w, r)
-				return
-			}
-
-			headerParts := strings.Split(authHeader, " ")
-			if len(headerParts) != 2 || headerParts[0] != "Bearer" {
+			tokenStr, ok := BearerToken(r)
+			if !ok {
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			tokenStr := headerParts[1]
 			tokenPrefix := tokenStr
 			if len(tokenStr) > 20 {
 				tokenPrefix = tokenStr[:20] + "..."
 			}
 			slog.Debug("parsing token in AuthOptionalCtx", slog.String("token_prefix", tokenPrefix))
-			
+
 			user, err := authService.ParseToken(r.Context(), tokenStr)
 			if err != nil {
 				slog.Debug("failed to parse token in AuthOptionalCtx", slog.String("error", err.Error()))
@@ -62,19 +64,17 @@ func AuthOptionalCtx(authService AuthService) func(http.Handler) http.Handler {
 func AuthCtx(authService AuthService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
+			if r.Header.Get("Authorization") == "" {
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			headerParts := strings.Split(authHeader, " ")
-			if len(headerParts) != 2 || headerParts[0] != "Bearer" {
+			tokenStr, ok := BearerToken(r)
+			if !ok {
 				http.Error(w, "invalid auth header", http.StatusUnauthorized)
 				return
 			}
 
-			tokenStr := headerParts[1]
 			user, err := authService.ParseToken(r.Context(), tokenStr)
 			if err != nil {
 				http.Error(w, "invalid token", http.StatusUnauthorized)
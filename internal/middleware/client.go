@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// clientIPKey is the key for the client IP address resolved by ClientIPCtx in the context.
+const clientIPKey contextKey = "clientIP"
+
+// ParseTrustedProxies parses IP addresses and CIDR prefixes of trusted reverse proxies.
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// ClientIPCtx resolves the IP address of clients behind the trusted reverse proxies.
+// Forwarding headers are only believed when the request comes from a trusted proxy:
+// X-Forwarded-For is read from right to left and the first hop that is not a trusted
+// proxy is the client, while X-Real-IP is used when X-Forwarded-For is missing.
+// Requests from anywhere else are attributed to the peer address, so clients
+// cannot choose the address they are rate limited by.
+func ClientIPCtx(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), clientIPKey, resolveClientIP(r, trusted))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func resolveClientIP(r *http.Request, trusted []netip.Prefix) string {
+	peer := remoteIP(r)
+	addr, err := netip.ParseAddr(peer)
+	if err != nil || !isTrustedProxy(addr, trusted) {
+		return peer
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				// Nothing left of a malformed hop can be trusted
+				break
+			}
+			addr = hop
+			if !isTrustedProxy(hop, trusted) {
+				break
+			}
+		}
+		return addr.Unmap().String()
+	}
+
+	if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return realIP.Unmap().String()
+	}
+	return peer
+}
+
+func isTrustedProxy(addr netip.Addr, trusted []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the address of the peer r comes from, without the port.
+func remoteIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// ClientIP returns the IP address a request comes from: the one resolved by
+// ClientIPCtx, or the peer address if the middleware is not in use.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// ClientInfo returns the user agent and IP address a request comes from.
+func ClientInfo(r *http.Request) domain.ClientInfo {
+	return domain.ClientInfo{UserAgent: r.UserAgent(), IP: ClientIP(r)}
+}
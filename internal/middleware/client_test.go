@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIPCtx(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string][]string
+		expectedIP string
+	}{
+		{
+			name:       "Direct client",
+			remoteAddr: "198.51.100.7:5000",
+			expectedIP: "198.51.100.7",
+		},
+		{
+			name:       "Headers from an untrusted peer are ignored",
+			remoteAddr: "198.51.100.7:5000",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9"}, "X-Real-Ip": {"203.0.113.9"}},
+			expectedIP: "198.51.100.7",
+		},
+		{
+			name:       "Client behind a trusted proxy",
+			remoteAddr: "10.0.0.5:5000",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9"}},
+			expectedIP: "203.0.113.9",
+		},
+		{
+			name:       "Spoofed hops left of the client are ignored",
+			remoteAddr: "10.0.0.5:5000",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.2.3.4, 203.0.113.9, 192.0.2.1"}},
+			expectedIP: "203.0.113.9",
+		},
+		{
+			name:       "Multiple header lines",
+			remoteAddr: "10.0.0.5:5000",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.2.3.4", "203.0.113.9"}},
+			expectedIP: "203.0.113.9",
+		},
+		{
+			name:       "Malformed hop stops at the last trusted proxy",
+			remoteAddr: "10.0.0.5:5000",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9, garbage, 10.0.0.6"}},
+			expectedIP: "10.0.0.6",
+		},
+		{
+			name:       "X-Real-IP from a trusted proxy",
+			remoteAddr: "192.0.2.1:5000",
+			headers:    map[string][]string{"X-Real-Ip": {"203.0.113.9"}},
+			expectedIP: "203.0.113.9",
+		},
+		{
+			name:       "Trusted proxy without forwarding headers",
+			remoteAddr: "10.0.0.5:5000",
+			expectedIP: "10.0.0.5",
+		},
+		{
+			name:       "IPv6 client",
+			remoteAddr: "[2001:db8::1]:5000",
+			expectedIP: "2001:db8::1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := ClientIPCtx(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = ClientInfo(r).IP
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, values := range tt.headers {
+				for _, v := range values {
+					req.Header.Add(k, v)
+				}
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.expectedIP, got)
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+
+	_, err = ParseTrustedProxies([]string{"proxy.local"})
+	assert.Error(t, err)
+}
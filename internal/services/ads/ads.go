@@ -4,16 +4,45 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/felix-kado/vk-test-task/internal/domain"
 	"github.com/felix-kado/vk-test-task/internal/services"
 	"github.com/felix-kado/vk-test-task/internal/storage"
 )
 
+const (
+	// maxSearchQueryLength limits the length of a full-text search query.
+	maxSearchQueryLength = 200
+
+	// defaultSuggestLimit and maxSuggestLimit bound the number of autocomplete suggestions.
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 20
+
+	// maxRadiusKm limits the radius of a location search.
+	maxRadiusKm = 500
+
+	// maxCityLength limits the length of the free-form city of an ad.
+	maxCityLength = 100
+)
+
 // AdRepository defines the interface for ad storage.
 type AdRepository interface {
 	CreateAd(ctx context.Context, ad *domain.Ad) (int64, error)
+	GetAdByID(ctx context.Context, id int64) (*domain.Ad, error)
+	UpdateAd(ctx context.Context, ad *domain.Ad, editorID int64) error
+	ListAdVersions(ctx context.Context, adID int64) ([]domain.AdVersion, error)
+	UpdateAdStatus(ctx context.Context, ad *domain.Ad, from domain.AdStatus) error
+	BumpAd(ctx context.Context, ad *domain.Ad, previous time.Time) error
+	DeleteAd(ctx context.Context, id int64) error
 	ListAds(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error)
+	CountAds(ctx context.Context, params *domain.ListAdsParams) (int64, error)
+	SuggestTitles(ctx context.Context, prefix string, limit int) ([]string, error)
+	CountAdsByCategory(ctx context.Context, params *domain.ListAdsParams) ([]domain.FacetCount, error)
+	CountAdsByPriceBucket(ctx context.Context, params *domain.ListAdsParams, bounds []int64) (map[int]int64, error)
+	CountAdsByAuthor(ctx context.Context, params *domain.ListAdsParams, limit int) ([]domain.FacetCount, error)
+	ListExchangeRates(ctx context.Context) ([]domain.ExchangeRate, error)
 }
 
 // UserRepository defines the interface for user-related operations needed by ads service.
@@ -23,23 +52,56 @@ type UserRepository interface {
 
 // Service provides ad-related operations.
 type Service struct {
-	adRepo   AdRepository
-	userRepo UserRepository
+	adRepo       AdRepository
+	userRepo     UserRepository
+	categoryRepo CategoryRepository
+	imageFetcher ImageFetcher
+	imagePolicy  ImagePolicy
+	lifecycle    LifecyclePolicy
+	now          func() time.Time
 }
 
 // New creates a new ad service.
-func New(adRepo AdRepository, userRepo UserRepository) *Service {
+func New(adRepo AdRepository, userRepo UserRepository, categoryRepo CategoryRepository, imageFetcher ImageFetcher, imagePolicy ImagePolicy, lifecycle LifecyclePolicy) *Service {
 	return &Service{
-		adRepo:   adRepo,
-		userRepo: userRepo,
+		adRepo:       adRepo,
+		userRepo:     userRepo,
+		categoryRepo: categoryRepo,
+		imageFetcher: imageFetcher,
+		imagePolicy:  imagePolicy,
+		lifecycle:    lifecycle,
+		now:          time.Now,
 	}
 }
 
-// CreateAd creates a new ad after validating it.
+// CreateAd creates a new ad after validating it. Ads are published unless
+// created as drafts.
 func (s *Service) CreateAd(ctx context.Context, ad *domain.Ad) (int64, error) {
+	if ad.Status == "" {
+		ad.Status = domain.AdStatusPublished
+	}
+	if ad.Status != domain.AdStatusPublished && ad.Status != domain.AdStatusDraft {
+		return 0, fmt.Errorf("%w: a new ad must be a draft or published", services.ErrInvalidInput)
+	}
+	if ad.Currency == "" {
+		ad.Currency = domain.BaseCurrency
+	}
+	if !ad.Currency.Valid() {
+		return 0, fmt.Errorf("%w: unsupported currency %q", services.ErrInvalidInput, ad.Currency)
+	}
+	if err := s.checkExchangeRate(ctx, ad.Currency); err != nil {
+		return 0, err
+	}
 	if err := s.validateAd(ad); err != nil {
 		return 0, fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
 	}
+	if err := s.validateAttributes(ctx, ad); err != nil {
+		return 0, err
+	}
+	coverAsImages(ad)
+	if err := s.validateImages(ctx, ad, nil); err != nil {
+		return 0, err
+	}
 
 	// Fetch user to get the login for denormalization
 	user, err := s.userRepo.FindUserByID(ctx, ad.UserID)
@@ -52,9 +114,13 @@ func (s *Service) CreateAd(ctx context.Context, ad *domain.Ad) (int64, error) {
 		return 0, fmt.Errorf("userRepo.FindUserByID: %w", err)
 	}
 	ad.AuthorLogin = user.Login
+	ad.ExpiresAt = s.now().Add(s.lifecycle.AdTTL)
 
 	adID, err := s.adRepo.CreateAd(ctx, ad)
 	if err != nil {
+		if errors.Is(err, storage.ErrCategoryNotFound) {
+			return 0, fmt.Errorf("%w: category not found", services.ErrInvalidInput)
+		}
 		if errors.Is(err, storage.ErrForeignKeyViolation) {
 			// This error is now less likely to be triggered by a missing user, but we keep it for other FK constraints.
 			return 0, fmt.Errorf("%w: invalid data reference", services.ErrInvalidInput)
@@ -67,6 +133,123 @@ func (s *Service) CreateAd(ctx context.Context, ad *domain.Ad) (int64, error) {
 	return adID, nil
 }
 
+// GetAdByID returns a single ad by its ID.
+func (s *Service) GetAdByID(ctx context.Context, id int64) (*domain.Ad, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: ad ID must be positive", services.ErrInvalidInput)
+	}
+
+	ad, err := s.adRepo.GetAdByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrAdNotFound) {
+			return nil, services.ErrAdNotFound
+		}
+		return nil, fmt.Errorf("adRepo.GetAdByID: %w", err)
+	}
+	return ad, nil
+}
+
+// UpdateAd applies a partial update to an ad owned by userID and returns the updated ad.
+func (s *Service) UpdateAd(ctx context.Context, userID, adID int64, patch *domain.AdPatch) (*domain.Ad, error) {
+	ad, err := s.getOwnedAd(ctx, userID, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.Currency != nil {
+		if !patch.Currency.Valid() {
+			return nil, fmt.Errorf("%w: unsupported currency %q", services.ErrInvalidInput, *patch.Currency)
+		}
+		if err := s.checkExchangeRate(ctx, *patch.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only added images are fetched
+	coverAsImages(ad)
+	previousImages := ad.ImageURLs()
+
+	patch.Apply(ad)
+	if err := s.validateAd(ad); err != nil {
+		return nil, fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
+	}
+	if err := s.validateAttributes(ctx, ad); err != nil {
+		return nil, err
+	}
+	if err := s.validateImages(ctx, ad, previousImages); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveAd(ctx, ad, userID); err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+// checkExchangeRate returns an error wrapping services.ErrInvalidInput unless
+// prices in currency can be converted, i.e. there is an exchange rate for it.
+func (s *Service) checkExchangeRate(ctx context.Context, currency domain.Currency) error {
+	if currency == domain.BaseCurrency {
+		return nil
+	}
+
+	rates, err := s.adRepo.ListExchangeRates(ctx)
+	if err != nil {
+		return fmt.Errorf("adRepo.ListExchangeRates: %w", err)
+	}
+	for _, r := range rates {
+		if r.Currency == currency {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no exchange rate is set for %s", services.ErrInvalidInput, currency)
+}
+
+// saveAd stores the changes to an ad made by editorID.
+func (s *Service) saveAd(ctx context.Context, ad *domain.Ad, editorID int64) error {
+	if err := s.adRepo.UpdateAd(ctx, ad, editorID); err != nil {
+		if errors.Is(err, storage.ErrAdNotFound) {
+			return services.ErrAdNotFound
+		}
+		if errors.Is(err, storage.ErrCategoryNotFound) {
+			return fmt.Errorf("%w: category not found", services.ErrInvalidInput)
+		}
+		return fmt.Errorf("adRepo.UpdateAd: %w", err)
+	}
+	return nil
+}
+
+// DeleteAd deletes an ad owned by userID.
+func (s *Service) DeleteAd(ctx context.Context, userID, adID int64) error {
+	if _, err := s.getOwnedAd(ctx, userID, adID); err != nil {
+		return err
+	}
+
+	if err := s.adRepo.DeleteAd(ctx, adID); err != nil {
+		if errors.Is(err, storage.ErrAdNotFound) {
+			return services.ErrAdNotFound
+		}
+		return fmt.Errorf("adRepo.DeleteAd: %w", err)
+	}
+	return nil
+}
+
+// getOwnedAd fetches an ad and checks that it belongs to userID.
+func (s *Service) getOwnedAd(ctx context.Context, userID, adID int64) (*domain.Ad, error) {
+	if userID == 0 {
+		return nil, services.ErrUnauthorized
+	}
+
+	ad, err := s.GetAdByID(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if ad.UserID != userID {
+		return nil, services.ErrForbidden
+	}
+	return ad, nil
+}
+
 func (s *Service) validateAd(ad *domain.Ad) error {
 	if ad.Title == "" {
 		return errors.New("title is required")
@@ -83,6 +266,20 @@ func (s *Service) validateAd(ad *domain.Ad) error {
 	if ad.Price < 0 {
 		return errors.New("price must be non-negative")
 	}
+	if ad.CategoryID <= 0 {
+		return errors.New("category ID is required")
+	}
+	if (ad.Latitude == nil) != (ad.Longitude == nil) {
+		return errors.New("latitude and longitude must be set together")
+	}
+	if loc := ad.Location(); loc != nil {
+		if err := loc.Validate(); err != nil {
+			return err
+		}
+	}
+	if len(ad.City) > maxCityLength {
+		return errors.New("city is too long")
+	}
 	return nil
 }
 
@@ -94,6 +291,27 @@ func (s *Service) ListAds(ctx context.Context, params *domain.ListAdsParams) ([]
 
 	// Set defaults for unspecified parameters
 	params.SetDefaults()
+	if err := s.checkExchangeRate(ctx, params.Currency); err != nil {
+		return nil, err
+	}
+
+	if params.Cursor != "" {
+		cursor, err := domain.DecodeAdCursor(params.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
+		}
+		if cursor.SortBy != params.SortBy || cursor.Order != params.Order {
+			return nil, fmt.Errorf("%w: cursor does not match sort_by and order parameters", services.ErrInvalidInput)
+		}
+		if cursor.SortBy == "price" && cursor.Currency != params.Currency {
+			return nil, fmt.Errorf("%w: cursor does not match the currency parameter", services.ErrInvalidInput)
+		}
+		params.After = cursor
+	}
+
+	if err := s.resolveAttributeFilters(ctx, params); err != nil {
+		return nil, err
+	}
 
 	ads, err := s.adRepo.ListAds(ctx, params)
 	if err != nil {
@@ -102,15 +320,72 @@ func (s *Service) ListAds(ctx context.Context, params *domain.ListAdsParams) ([]
 	return ads, nil
 }
 
+// ListAdsPage returns a page of ads along with the total number of ads matching the filters.
+func (s *Service) ListAdsPage(ctx context.Context, params *domain.ListAdsParams) (*domain.AdPage, error) {
+	ads, err := s.ListAds(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.adRepo.CountAds(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("ads.CountAds: %w", err)
+	}
+	return &domain.AdPage{Ads: ads, Total: total}, nil
+}
+
+// SuggestTitles returns ad titles matching the prefix for search autocomplete.
+// Misspelled prefixes are matched fuzzily.
+func (s *Service) SuggestTitles(ctx context.Context, prefix string, limit int) ([]string, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: prefix is required", services.ErrInvalidInput)
+	}
+	if len(prefix) > maxSearchQueryLength {
+		return nil, fmt.Errorf("%w: prefix cannot exceed %d characters", services.ErrInvalidInput, maxSearchQueryLength)
+	}
+	if limit < 0 || limit > maxSuggestLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", services.ErrInvalidInput, maxSuggestLimit)
+	}
+	if limit == 0 {
+		limit = defaultSuggestLimit
+	}
+
+	titles, err := s.adRepo.SuggestTitles(ctx, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("adRepo.SuggestTitles: %w", err)
+	}
+	return titles, nil
+}
+
 // validateListParams validates the parameters for listing ads.
 func (s *Service) validateListParams(params *domain.ListAdsParams) error {
 	if params == nil {
 		return errors.New("params cannot be nil")
 	}
 
+	// Validate search query
+	params.Query = strings.TrimSpace(params.Query)
+	if len(params.Query) > maxSearchQueryLength {
+		return fmt.Errorf("q cannot exceed %d characters", maxSearchQueryLength)
+	}
+
 	// Validate sort_by if specified
-	if params.SortBy != "" && params.SortBy != "price" && params.SortBy != "created_at" {
-		return errors.New("invalid sort_by parameter: must be 'price' or 'created_at'")
+	switch params.SortBy {
+	case "", "price", "created_at":
+	case "relevance":
+		if params.Query == "" {
+			return errors.New("sort_by=relevance requires a search query q")
+		}
+	case "distance":
+		if params.Near == nil {
+			return errors.New("sort_by=distance requires a near location")
+		}
+	default:
+		return errors.New("invalid sort_by parameter: must be 'price', 'created_at', 'relevance' or 'distance'")
+	}
+	if (params.SortBy == "relevance" || params.SortBy == "distance") && (params.CursorMode || params.Cursor != "") {
+		return fmt.Errorf("cursor pagination is not supported with sort_by=%s", params.SortBy)
 	}
 
 	// Validate order if specified
@@ -139,6 +414,33 @@ func (s *Service) validateListParams(params *domain.ListAdsParams) error {
 	if params.MinPrice != nil && params.MaxPrice != nil && *params.MinPrice > *params.MaxPrice {
 		return errors.New("min_price cannot be greater than max_price")
 	}
+	if params.Currency != "" && !params.Currency.Valid() {
+		return fmt.Errorf("unsupported currency %q", params.Currency)
+	}
+
+	// Validate category filter
+	if params.CategoryID != nil && *params.CategoryID <= 0 {
+		return errors.New("category must be a positive ID")
+	}
+
+	if err := validateStatusFilter(params); err != nil {
+		return err
+	}
+
+	// Validate location filter
+	if params.Near != nil {
+		if err := params.Near.Validate(); err != nil {
+			return fmt.Errorf("invalid near parameter: %v", err)
+		}
+	}
+	if params.RadiusKm != nil {
+		if params.Near == nil {
+			return errors.New("radius_km requires a near location")
+		}
+		if *params.RadiusKm <= 0 || *params.RadiusKm > maxRadiusKm {
+			return fmt.Errorf("radius_km must be greater than 0 and at most %d", maxRadiusKm)
+		}
+	}
 
 	return nil
 }
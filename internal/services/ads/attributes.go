@@ -0,0 +1,92 @@
+package ads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+)
+
+// maxAttributeFilters limits the number of attribute filters in a single feed query.
+const maxAttributeFilters = 10
+
+// CategoryRepository defines the interface for category lookups needed by ads service.
+type CategoryRepository interface {
+	GetCategoryByID(ctx context.Context, id int64) (*domain.Category, error)
+}
+
+// getCategory fetches a category, mapping a missing one to invalid input.
+func (s *Service) getCategory(ctx context.Context, id int64) (*domain.Category, error) {
+	category, err := s.categoryRepo.GetCategoryByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrCategoryNotFound) {
+			return nil, fmt.Errorf("%w: category not found", services.ErrInvalidInput)
+		}
+		return nil, fmt.Errorf("categoryRepo.GetCategoryByID: %w", err)
+	}
+	return category, nil
+}
+
+// validateAttributes checks the ad attributes against the schema of its category.
+func (s *Service) validateAttributes(ctx context.Context, ad *domain.Ad) error {
+	category, err := s.getCategory(ctx, ad.CategoryID)
+	if err != nil {
+		return err
+	}
+	if err := category.AttributeSchema.Validate(ad.Attributes); err != nil {
+		return fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
+	}
+	return nil
+}
+
+// resolveAttributeFilters validates attribute filter keys and converts raw values
+// to typed ones. When the feed is filtered by a category with a schema, keys must
+// exist in it and values take the declared type; otherwise the type is inferred.
+func (s *Service) resolveAttributeFilters(ctx context.Context, params *domain.ListAdsParams) error {
+	if len(params.Attributes) == 0 {
+		return nil
+	}
+	if len(params.Attributes) > maxAttributeFilters {
+		return fmt.Errorf("%w: too many attribute filters (max %d)", services.ErrInvalidInput, maxAttributeFilters)
+	}
+
+	var schema *domain.AttributeSchema
+	if params.CategoryID != nil {
+		category, err := s.getCategory(ctx, *params.CategoryID)
+		if err != nil {
+			return err
+		}
+		schema = category.AttributeSchema
+	}
+
+	for i := range params.Attributes {
+		f := &params.Attributes[i]
+		if !domain.ValidAttributeKey(f.Key) {
+			return fmt.Errorf("%w: invalid attribute name %q", services.ErrInvalidInput, f.Key)
+		}
+		if f.Op != "eq" && f.Op != "min" && f.Op != "max" {
+			return fmt.Errorf("%w: invalid attribute filter operator %q", services.ErrInvalidInput, f.Op)
+		}
+
+		var prop *domain.AttributeSchema
+		if schema != nil {
+			prop = schema.Properties[f.Key]
+			if prop == nil {
+				return fmt.Errorf("%w: unknown attribute %q for this category", services.ErrInvalidInput, f.Key)
+			}
+		}
+
+		value, err := prop.ParseValue(f.Raw)
+		if err != nil {
+			return fmt.Errorf("%w: attribute %q %v", services.ErrInvalidInput, f.Key, err)
+		}
+		if _, isNumber := value.(float64); f.Op != "eq" && !isNumber {
+			return fmt.Errorf("%w: attribute %q range filter must be a number", services.ErrInvalidInput, f.Key)
+		}
+		f.Value = value
+	}
+	return nil
+}
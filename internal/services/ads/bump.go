@@ -0,0 +1,46 @@
+package ads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+)
+
+// BumpAd moves a published ad owned by userID to the top of the feed. An ad may be
+// bumped once per cooldown, counted from when it was posted or last bumped; earlier
+// attempts fail with a services.RetryAfterError wrapping services.ErrConflict.
+func (s *Service) BumpAd(ctx context.Context, userID, adID int64) (*domain.Ad, error) {
+	ad, err := s.getOwnedAd(ctx, userID, adID)
+	if err != nil {
+		return nil, err
+	}
+	if ad.Status != domain.AdStatusPublished {
+		return nil, fmt.Errorf("%w: a %s ad cannot be bumped", services.ErrConflict, ad.Status)
+	}
+
+	now := s.now()
+	if !now.Before(ad.ExpiresAt) {
+		return nil, fmt.Errorf("%w: the ad has expired, renew it instead", services.ErrConflict)
+	}
+	if wait := ad.BumpedAt.Add(s.lifecycle.BumpCooldown).Sub(now); wait > 0 {
+		return nil, &services.RetryAfterError{
+			Err:   fmt.Errorf("%w: the ad can be bumped again in %s", services.ErrConflict, wait.Round(time.Second)),
+			After: wait,
+		}
+	}
+
+	previous := ad.BumpedAt
+	ad.BumpedAt = now
+	if err := s.adRepo.BumpAd(ctx, ad, previous); err != nil {
+		if errors.Is(err, storage.ErrAdConflict) {
+			return nil, fmt.Errorf("%w: ad was bumped concurrently", services.ErrConflict)
+		}
+		return nil, fmt.Errorf("adRepo.BumpAd: %w", err)
+	}
+	return ad, nil
+}
@@ -0,0 +1,101 @@
+package ads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+)
+
+// expiryBatchSize is the number of expired ads archived in one transaction.
+const expiryBatchSize = 500
+
+// LifecyclePolicy controls how long ads stay on sale and how often they may be bumped.
+type LifecyclePolicy struct {
+	AdTTL        time.Duration // term of a published ad, restarted by publishing and renewal
+	BumpCooldown time.Duration // minimum time between posting or bumping an ad and the next bump
+}
+
+// RenewAd starts a new term for an ad owned by userID. Archived ads, including
+// those archived on expiry, are published again.
+func (s *Service) RenewAd(ctx context.Context, userID, adID int64) (*domain.Ad, error) {
+	ad, err := s.getOwnedAd(ctx, userID, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := ad.Status
+	switch from {
+	case domain.AdStatusPublished, domain.AdStatusReserved:
+	case domain.AdStatusArchived:
+		ad.Status = domain.AdStatusPublished
+	default:
+		return nil, fmt.Errorf("%w: a %s ad cannot be renewed", services.ErrConflict, from)
+	}
+	ad.ExpiresAt = s.now().Add(s.lifecycle.AdTTL)
+
+	if err := s.saveAdStatus(ctx, ad, from); err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+// ExpiryRepository defines the interface for archiving expired ads.
+type ExpiryRepository interface {
+	ArchiveExpiredAds(ctx context.Context, limit int) (int64, error)
+}
+
+// ExpiryScheduler periodically archives ads whose term has ended.
+// It is safe to run on every replica; the repository lets one of them work at a time.
+type ExpiryScheduler struct {
+	repo     ExpiryRepository
+	interval time.Duration
+	log      *slog.Logger
+}
+
+// NewExpiryScheduler creates a scheduler that runs every interval.
+func NewExpiryScheduler(repo ExpiryRepository, interval time.Duration, log *slog.Logger) *ExpiryScheduler {
+	return &ExpiryScheduler{repo: repo, interval: interval, log: log}
+}
+
+// Run archives expired ads until ctx is canceled.
+func (s *ExpiryScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.archiveExpired(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// archiveExpired archives expired ads in batches until none are left.
+func (s *ExpiryScheduler) archiveExpired(ctx context.Context) {
+	var total int64
+	for ctx.Err() == nil {
+		n, err := s.repo.ArchiveExpiredAds(ctx, expiryBatchSize)
+		if err != nil {
+			if !errors.Is(err, storage.ErrLocked) && ctx.Err() == nil {
+				s.log.Error("failed to archive expired ads", slog.String("error", err.Error()))
+			}
+			break
+		}
+		total += n
+		if n < expiryBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		s.log.Info("archived expired ads", slog.Int64("count", total))
+	}
+}
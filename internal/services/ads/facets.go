@@ -0,0 +1,84 @@
+package ads
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+)
+
+const (
+	// maxPriceBuckets limits the number of bounds in a price histogram.
+	maxPriceBuckets = 20
+
+	// authorFacetLimit is the number of top authors returned in facets.
+	authorFacetLimit = 20
+)
+
+// GetFacets returns category, price and author counts for ads matching params.
+// Category and price counts ignore their own filter, so the sidebar keeps showing
+// the alternatives to the currently selected value.
+func (s *Service) GetFacets(ctx context.Context, params *domain.ListAdsParams, priceBounds []int64) (*domain.AdFacets, error) {
+	if err := s.validateListParams(params); err != nil {
+		return nil, fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
+	}
+	if err := validatePriceBounds(priceBounds); err != nil {
+		return nil, fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
+	}
+	if len(priceBounds) == 0 {
+		priceBounds = domain.DefaultPriceBuckets
+	}
+	if params.Currency == "" {
+		params.Currency = domain.BaseCurrency
+	}
+	if err := s.checkExchangeRate(ctx, params.Currency); err != nil {
+		return nil, err
+	}
+	if err := s.resolveAttributeFilters(ctx, params); err != nil {
+		return nil, err
+	}
+
+	categoryParams := *params
+	categoryParams.CategoryID = nil
+	// Attribute filters are only meaningful within the selected category
+	categoryParams.Attributes = nil
+	categories, err := s.adRepo.CountAdsByCategory(ctx, &categoryParams)
+	if err != nil {
+		return nil, fmt.Errorf("adRepo.CountAdsByCategory: %w", err)
+	}
+
+	priceParams := *params
+	priceParams.MinPrice, priceParams.MaxPrice = nil, nil
+	priceCounts, err := s.adRepo.CountAdsByPriceBucket(ctx, &priceParams, priceBounds)
+	if err != nil {
+		return nil, fmt.Errorf("adRepo.CountAdsByPriceBucket: %w", err)
+	}
+
+	authors, err := s.adRepo.CountAdsByAuthor(ctx, params, authorFacetLimit)
+	if err != nil {
+		return nil, fmt.Errorf("adRepo.CountAdsByAuthor: %w", err)
+	}
+
+	return &domain.AdFacets{
+		Categories:   categories,
+		PriceBuckets: domain.NewPriceBuckets(priceBounds, priceCounts),
+		Authors:      authors,
+	}, nil
+}
+
+// validatePriceBounds checks that histogram bounds are non-negative and strictly ascending.
+func validatePriceBounds(bounds []int64) error {
+	if len(bounds) > maxPriceBuckets {
+		return fmt.Errorf("price_buckets cannot have more than %d bounds", maxPriceBuckets)
+	}
+	for i, b := range bounds {
+		if b < 0 {
+			return fmt.Errorf("price_buckets must be non-negative")
+		}
+		if i > 0 && b <= bounds[i-1] {
+			return fmt.Errorf("price_buckets must be strictly ascending")
+		}
+	}
+	return nil
+}
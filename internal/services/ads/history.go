@@ -0,0 +1,51 @@
+package ads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+)
+
+// GetAdHistory returns the previous versions of an ad, the most recent first.
+// The history is available to the owner of the ad and to administrators.
+func (s *Service) GetAdHistory(ctx context.Context, userID, adID int64) ([]domain.AdVersion, error) {
+	if userID == 0 {
+		return nil, services.ErrUnauthorized
+	}
+
+	ad, err := s.GetAdByID(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if ad.UserID != userID {
+		admin, err := s.isAdmin(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !admin {
+			return nil, services.ErrForbidden
+		}
+	}
+
+	versions, err := s.adRepo.ListAdVersions(ctx, adID)
+	if err != nil {
+		return nil, fmt.Errorf("adRepo.ListAdVersions: %w", err)
+	}
+	return versions, nil
+}
+
+// isAdmin reports whether userID is an administrator.
+func (s *Service) isAdmin(ctx context.Context, userID int64) (bool, error) {
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return false, services.ErrUnauthorized
+		}
+		return false, fmt.Errorf("userRepo.FindUserByID: %w", err)
+	}
+	return user.IsAdmin, nil
+}
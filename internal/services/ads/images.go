@@ -0,0 +1,183 @@
+package ads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+)
+
+const (
+	// maxImageURLLength matches the size of the image_url column.
+	maxImageURLLength = 255
+
+	// maxAdImages limits the number of images of an ad.
+	maxAdImages = 10
+)
+
+// ImageFetcher reads the metadata of a remote image without downloading it.
+type ImageFetcher interface {
+	FetchImageInfo(ctx context.Context, url string) (*domain.ImageInfo, error)
+}
+
+// ImagePolicy limits the images an ad may reference.
+type ImagePolicy struct {
+	AllowedSchemes []string      // e.g. "https"
+	AllowedHosts   []string      // host names, or ".example.com" for a domain and its subdomains; empty allows any host
+	MaxSize        int64         // maximum image size in bytes
+	FetchTimeout   time.Duration // timeout for reading the image metadata
+	UploadBaseURL  string        // images under this URL were uploaded through the API and skip the scheme and host checks
+}
+
+// hostAllowed reports whether the policy allows images from host.
+func (p *ImagePolicy) hostAllowed(host string) bool {
+	if len(p.AllowedHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, allowed := range p.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || (strings.HasPrefix(allowed, ".") && (strings.HasSuffix(host, allowed) || host == allowed[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateImageURL checks that rawURL points to an allowed host and serves an image
+// of a supported format and size. An empty URL means the ad has no image.
+func (s *Service) validateImageURL(ctx context.Context, rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	if err := s.imagePolicy.checkURL(rawURL); err != nil {
+		return fmt.Errorf("%w: image_url %v", services.ErrInvalidInput, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.imagePolicy.FetchTimeout)
+	defer cancel()
+
+	info, err := s.imageFetcher.FetchImageInfo(ctx, rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: image_url could not be fetched", services.ErrInvalidInput)
+	}
+	if !domain.IsSupportedImageType(info.ContentType) {
+		return fmt.Errorf("%w: image must be a JPEG, PNG or WebP file", services.ErrInvalidInput)
+	}
+	switch {
+	case info.Size < 0:
+		return fmt.Errorf("%w: image size could not be determined", services.ErrInvalidInput)
+	case info.Size == 0:
+		return fmt.Errorf("%w: image is empty", services.ErrInvalidInput)
+	case info.Size > s.imagePolicy.MaxSize:
+		return fmt.Errorf("%w: image cannot exceed %d bytes", services.ErrInvalidInput, s.imagePolicy.MaxSize)
+	}
+	return nil
+}
+
+// validateImages checks the image list of ad. Only images not in previous,
+// the URLs the ad already had, are fetched.
+func (s *Service) validateImages(ctx context.Context, ad *domain.Ad, previous []string) error {
+	if len(ad.Images) > maxAdImages {
+		return fmt.Errorf("%w: an ad can have at most %d images", services.ErrInvalidInput, maxAdImages)
+	}
+	for i, img := range ad.Images {
+		if img.URL == "" {
+			return fmt.Errorf("%w: image URL cannot be empty", services.ErrInvalidInput)
+		}
+		if slices.ContainsFunc(ad.Images[:i], func(other domain.AdImage) bool { return other.URL == img.URL }) {
+			return fmt.Errorf("%w: images must be unique", services.ErrInvalidInput)
+		}
+		if slices.Contains(previous, img.URL) {
+			continue
+		}
+		if err := s.validateImageURL(ctx, img.URL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// coverAsImages turns a lone ImageURL into the image list, for callers that only set the cover.
+func coverAsImages(ad *domain.Ad) {
+	if len(ad.Images) == 0 && ad.ImageURL != "" {
+		ad.SetImageURLs([]string{ad.ImageURL})
+	}
+}
+
+// ReorderAdImages puts the images of an ad owned by userID in the order of imageIDs,
+// which must list every image of the ad once. The first image becomes the cover.
+func (s *Service) ReorderAdImages(ctx context.Context, userID, adID int64, imageIDs []int64) (*domain.Ad, error) {
+	ad, err := s.getOwnedAd(ctx, userID, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(imageIDs) != len(ad.Images) {
+		return nil, fmt.Errorf("%w: image_ids must list every image of the ad once", services.ErrInvalidInput)
+	}
+	urls := make([]string, len(imageIDs))
+	for i, id := range imageIDs {
+		j := slices.IndexFunc(ad.Images, func(img domain.AdImage) bool { return img.ID == id })
+		if j < 0 || slices.Contains(imageIDs[:i], id) {
+			return nil, fmt.Errorf("%w: image_ids must list every image of the ad once", services.ErrInvalidInput)
+		}
+		urls[i] = ad.Images[j].URL
+	}
+	ad.SetImageURLs(urls)
+
+	if err := s.saveAd(ctx, ad, userID); err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+// RemoveAdImage removes an image from an ad owned by userID. Removing the cover
+// makes the next image the cover.
+func (s *Service) RemoveAdImage(ctx context.Context, userID, adID, imageID int64) (*domain.Ad, error) {
+	ad, err := s.getOwnedAd(ctx, userID, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(ad.Images, func(img domain.AdImage) bool { return img.ID == imageID })
+	if i < 0 {
+		return nil, services.ErrImageNotFound
+	}
+	ad.SetImageURLs(slices.Delete(ad.ImageURLs(), i, i+1))
+
+	if err := s.saveAd(ctx, ad, userID); err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+// checkURL validates the parts of an image URL that do not require fetching it.
+func (p *ImagePolicy) checkURL(rawURL string) error {
+	if len(rawURL) > maxImageURLLength {
+		return errors.New("is too long")
+	}
+	if p.UploadBaseURL != "" && strings.HasPrefix(rawURL, p.UploadBaseURL) {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return errors.New("must be an absolute URL")
+	}
+	if !slices.Contains(p.AllowedSchemes, u.Scheme) {
+		return fmt.Errorf("must use one of the schemes: %s", strings.Join(p.AllowedSchemes, ", "))
+	}
+	if u.User != nil {
+		return errors.New("must not contain credentials")
+	}
+	if !p.hostAllowed(u.Hostname()) {
+		return errors.New("host is not allowed")
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package ads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+)
+
+// adTransitions lists the statuses an ad may move to from each status.
+var adTransitions = map[domain.AdStatus][]domain.AdStatus{
+	domain.AdStatusDraft:     {domain.AdStatusPublished, domain.AdStatusArchived},
+	domain.AdStatusPublished: {domain.AdStatusReserved, domain.AdStatusSold, domain.AdStatusArchived},
+	domain.AdStatusReserved:  {domain.AdStatusPublished, domain.AdStatusSold, domain.AdStatusArchived},
+	domain.AdStatusSold:      {domain.AdStatusArchived},
+	domain.AdStatusArchived:  {domain.AdStatusDraft, domain.AdStatusPublished},
+}
+
+// canTransition reports whether an ad may move from status from to status to.
+func canTransition(from, to domain.AdStatus) bool {
+	return slices.Contains(adTransitions[from], to)
+}
+
+// ChangeAdStatus moves an ad owned by userID to a new status along an allowed transition.
+// Setting the current status again is a no-op. Publishing starts a new term.
+func (s *Service) ChangeAdStatus(ctx context.Context, userID, adID int64, status domain.AdStatus) (*domain.Ad, error) {
+	if !status.Valid() {
+		return nil, fmt.Errorf("%w: unknown status %q", services.ErrInvalidInput, status)
+	}
+
+	ad, err := s.getOwnedAd(ctx, userID, adID)
+	if err != nil {
+		return nil, err
+	}
+	if ad.Status == status {
+		return ad, nil
+	}
+	if !canTransition(ad.Status, status) {
+		return nil, fmt.Errorf("%w: cannot change status from %s to %s", services.ErrConflict, ad.Status, status)
+	}
+
+	from := ad.Status
+	ad.Status = status
+	// Reserved ads go back on sale for the rest of their term
+	if status == domain.AdStatusPublished && from != domain.AdStatusReserved {
+		ad.ExpiresAt = s.now().Add(s.lifecycle.AdTTL)
+	}
+	if err := s.saveAdStatus(ctx, ad, from); err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+// saveAdStatus stores the status and expiry of an ad whose status was from.
+func (s *Service) saveAdStatus(ctx context.Context, ad *domain.Ad, from domain.AdStatus) error {
+	if err := s.adRepo.UpdateAdStatus(ctx, ad, from); err != nil {
+		if errors.Is(err, storage.ErrAdConflict) {
+			return fmt.Errorf("%w: ad status was changed concurrently", services.ErrConflict)
+		}
+		return fmt.Errorf("adRepo.UpdateAdStatus: %w", err)
+	}
+	return nil
+}
+
+// GetVisibleAd returns an ad if viewerID may see it: drafts and archived ads are
+// visible to their owner only. viewerID is 0 for anonymous requests.
+func (s *Service) GetVisibleAd(ctx context.Context, viewerID, adID int64) (*domain.Ad, error) {
+	ad, err := s.GetAdByID(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if !ad.Status.Public() && ad.UserID != viewerID {
+		return nil, services.ErrAdNotFound
+	}
+	return ad, nil
+}
+
+// validateStatusFilter checks the statuses requested in params. Only listed
+// statuses are available to everyone; owners may filter their own ads by any status.
+func validateStatusFilter(params *domain.ListAdsParams) error {
+	for _, st := range params.Statuses {
+		if !st.Valid() {
+			return fmt.Errorf("unknown status %q", st)
+		}
+		if params.OwnerID == nil && !st.Listed() {
+			return fmt.Errorf("status %s is only available for your own ads", st)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"regexp"
@@ -18,32 +19,45 @@ import (
 
 // Service provides user authentication operations.
 type Service struct {
-	userRepo storage.UserRepository
-	secret   []byte
-	tokenTTL time.Duration
+	userRepo    storage.UserRepository
+	sessions    storage.SessionRepository
+	revocations storage.RevocationStore
+	attempts    storage.AttemptStore
+	keys        *Keys
+	tokenTTL    time.Duration
+	refreshTTL  time.Duration
+	lockout     LockoutPolicy
+	now         func() time.Time
 }
 
-// New creates a new auth service.
-func New(userRepo storage.UserRepository, secret string, tokenTTL time.Duration) *Service {
+// New creates a new auth service. Access tokens are signed with keys and live for tokenTTL;
+// refresh tokens live for refreshTTL. Failed logins are counted in attempts and limited by lockout.
+func New(userRepo storage.UserRepository, sessions storage.SessionRepository, revocations storage.RevocationStore, attempts storage.AttemptStore, keys *Keys, tokenTTL, refreshTTL time.Duration, lockout LockoutPolicy) *Service {
 	return &Service{
-		userRepo: userRepo,
-		secret:   []byte(secret),
-		tokenTTL: tokenTTL,
+		userRepo:    userRepo,
+		sessions:    sessions,
+		revocations: revocations,
+		attempts:    attempts,
+		keys:        keys,
+		tokenTTL:    tokenTTL,
+		refreshTTL:  refreshTTL,
+		lockout:     lockout,
+		now:         time.Now,
 	}
 }
 
-// Register creates a new user and returns a JWT token.
-func (s *Service) Register(ctx context.Context, login, password string) (string, *domain.User, error) {
+// Register creates a new user and returns a token pair for them, starting a session for client.
+func (s *Service) Register(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, *domain.User, error) {
 	if err := validateLogin(login); err != nil {
-		return "", nil, fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
+		return nil, nil, fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
 	}
 	if err := validatePassword(password); err != nil {
-		return "", nil, fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
+		return nil, nil, fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
 	}
 
 	passHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to hash password: %w", err)
+		return nil, nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	u := &domain.User{
@@ -53,83 +67,133 @@ func (s *Service) Register(ctx context.Context, login, password string) (string,
 
 	if err := s.userRepo.CreateUser(ctx, u); err != nil {
 		if errors.Is(err, storage.ErrUserExists) {
-			return "", nil, services.ErrUserExists
+			return nil, nil, services.ErrUserExists
 		}
-		return "", nil, fmt.Errorf("failed to create user: %w", err)
+		return nil, nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	token, err := s.generateToken(u)
+	tokens, err := s.issueTokens(ctx, u, nil, client)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, nil, err
 	}
 
-	return token, u, nil
+	return tokens, u, nil
 }
 
-// Login authenticates a user and returns a JWT token.
-func (s *Service) Login(ctx context.Context, login, password string) (string, error) {
+// Login authenticates a user and returns a token pair, starting a session for client.
+// Too many failed logins with the same login or from the same IP address lock them
+// out for a while; Login then fails with a services.RetryAfterError.
+func (s *Service) Login(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.TokenPair, error) {
 	if err := validateLogin(login); err != nil {
-		return "", fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
+		return nil, fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
 	}
 	if password == "" {
-		return "", fmt.Errorf("%w: password is required", services.ErrInvalidInput)
+		return nil, fmt.Errorf("%w: password is required", services.ErrInvalidInput)
 	}
 
+	attempt, err := s.startAttempt(ctx, login, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// The attempt is already counted as failed, and is only reverted once the password
+	// matches or the credentials could not be checked at all
 	u, err := s.userRepo.FindByLogin(ctx, login)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
-			return "", services.ErrInvalidCredentials
+			// Unknown logins count too, so that guessing them is limited as well
+			return nil, services.ErrInvalidCredentials
 		}
-		return "", err
+		return nil, s.abortAttempt(ctx, attempt, err)
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
-		return "", services.ErrInvalidCredentials
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return nil, services.ErrInvalidCredentials
+		}
+		return nil, s.abortAttempt(ctx, attempt, fmt.Errorf("failed to compare password hash: %w", err))
 	}
 
-	token, err := s.generateToken(u)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
+	if err := s.revertAttempt(ctx, attempt, true); err != nil {
+		return nil, err
 	}
 
-	return token, nil
+	return s.issueTokens(ctx, u, nil, client)
 }
 
-// ParseToken parses a JWT token and returns the user associated with it.
+// ParseToken parses a JWT token and returns the user associated with it. Tokens
+// that were revoked, belong to a revoked session or were issued before the user
+// logged out everywhere are rejected.
 func (s *Service) ParseToken(ctx context.Context, tokenStr string) (*domain.User, error) {
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return s.secret, nil
-	})
-
+	claims, err := s.parseClaims(tokenStr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse token: %w", err)
+		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		sub, err := claims.GetSubject()
+	if claims.ID != "" {
+		revoked, err := s.revocations.IsTokenRevoked(ctx, claims.ID)
 		if err != nil {
-			return nil, fmt.Errorf("invalid subject in token: %w", err)
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
 		}
-
-		var userID int64
-		if _, err := fmt.Sscanf(sub, "%d", &userID); err != nil {
-			return nil, fmt.Errorf("failed to parse user ID from token subject: %w", err)
+		if revoked {
+			return nil, errors.New("token has been revoked")
 		}
+	}
 
-		u, err := s.userRepo.FindUserByID(ctx, userID)
-		if err != nil {
-			if errors.Is(err, storage.ErrUserNotFound) {
-				return nil, services.ErrUserNotFound
+	if claims.SessionID != "" {
+		if err := s.sessions.TouchSession(ctx, claims.SessionID); err != nil {
+			if errors.Is(err, storage.ErrSessionNotFound) {
+				return nil, errors.New("session has been revoked")
 			}
-			return nil, fmt.Errorf("failed to find user by id: %w", err)
+			return nil, fmt.Errorf("failed to check session: %w", err)
+		}
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse user ID from token subject: %w", err)
+	}
+
+	u, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, services.ErrUserNotFound
 		}
-		return u, nil
+		return nil, fmt.Errorf("failed to find user by id: %w", err)
+	}
+	if claims.Version != u.TokenVersion {
+		return nil, errors.New("token has been revoked")
+	}
+
+	return u, nil
+}
+
+// PublicKeys returns the keys other services can verify access tokens with.
+func (s *Service) PublicKeys() []domain.PublicKey {
+	return s.keys.PublicKeys()
+}
+
+// tokenClaims are the claims of an access token. Tokens issued before jti, ver
+// and sid were added have no ID, version 0 and no session.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Version   int    `json:"ver"`
+	SessionID string `json:"sid,omitempty"`
+}
+
+// parseClaims verifies the signature and expiry of an access token and returns its claims.
+func (s *Service) parseClaims(tokenStr string) (*tokenClaims, error) {
+	claims := &tokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, s.keys.keyFunc)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
 	}
 
-	return nil, errors.New("invalid token")
+	return claims, nil
 }
 
 var (
@@ -207,13 +271,17 @@ func isSpecialCharacter(r rune) bool {
 	return false
 }
 
-func (s *Service) generateToken(u *domain.User) (string, error) {
-	claims := jwt.MapClaims{
-		"sub": strconv.FormatInt(u.ID, 10),
-		"iat": time.Now().Unix(),
-		"exp": time.Now().Add(s.tokenTTL).Unix(),
+func (s *Service) generateToken(u *domain.User, sessionID string, now time.Time) (string, error) {
+	claims := tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        rand.Text(),
+			Subject:   strconv.FormatInt(u.ID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.tokenTTL)),
+		},
+		Version:   u.TokenVersion,
+		SessionID: sessionID,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secret)
+	return s.keys.sign(claims)
 }
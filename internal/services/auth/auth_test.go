@@ -10,6 +10,7 @@ import (
 	"github.com/felix-kado/vk-test-task/internal/domain"
 	"github.com/felix-kado/vk-test-task/internal/services"
 	"github.com/felix-kado/vk-test-task/internal/storage"
+	"github.com/felix-kado/vk-test-task/internal/storage/memory"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
@@ -20,6 +21,8 @@ type mockUserRepository struct {
 	CreateUserFunc   func(ctx context.Context, u *domain.User) error
 	FindByLoginFunc  func(ctx context.Context, login string) (*domain.User, error)
 	FindUserByIDFunc func(ctx context.Context, id int64) (*domain.User, error)
+
+	IncrementTokenVersionFunc func(ctx context.Context, id int64) error
 }
 
 func (m *mockUserRepository) CreateUser(ctx context.Context, u *domain.User) error {
@@ -34,6 +37,64 @@ func (m *mockUserRepository) FindUserByID(ctx context.Context, id int64) (*domai
 	return m.FindUserByIDFunc(ctx, id)
 }
 
+func (m *mockUserRepository) IncrementTokenVersion(ctx context.Context, id int64) error {
+	return m.IncrementTokenVersionFunc(ctx, id)
+}
+
+// mockSessionRepository is a mock implementation of SessionRepository for testing.
+type mockSessionRepository struct {
+	CreateSessionFunc      func(ctx context.Context, session *domain.Session, t *domain.RefreshToken) error
+	TouchSessionFunc       func(ctx context.Context, id string) error
+	ListSessionsFunc       func(ctx context.Context, userID int64) ([]domain.Session, error)
+	RevokeSessionFunc      func(ctx context.Context, userID int64, id string) error
+	RevokeUserSessionsFunc func(ctx context.Context, userID int64) error
+	FindRefreshTokenFunc   func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	RotateRefreshTokenFunc func(ctx context.Context, usedID int64, next *domain.RefreshToken) error
+}
+
+func (m *mockSessionRepository) CreateSession(ctx context.Context, session *domain.Session, t *domain.RefreshToken) error {
+	if m.CreateSessionFunc == nil {
+		session.ID = "session"
+		t.FamilyID = session.ID
+		return nil
+	}
+	return m.CreateSessionFunc(ctx, session, t)
+}
+
+func (m *mockSessionRepository) TouchSession(ctx context.Context, id string) error {
+	if m.TouchSessionFunc == nil {
+		return nil
+	}
+	return m.TouchSessionFunc(ctx, id)
+}
+
+func (m *mockSessionRepository) ListSessions(ctx context.Context, userID int64) ([]domain.Session, error) {
+	return m.ListSessionsFunc(ctx, userID)
+}
+
+func (m *mockSessionRepository) RevokeSession(ctx context.Context, userID int64, id string) error {
+	return m.RevokeSessionFunc(ctx, userID, id)
+}
+
+func (m *mockSessionRepository) RevokeUserSessions(ctx context.Context, userID int64) error {
+	return m.RevokeUserSessionsFunc(ctx, userID)
+}
+
+func (m *mockSessionRepository) FindRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	return m.FindRefreshTokenFunc(ctx, tokenHash)
+}
+
+func (m *mockSessionRepository) RotateRefreshToken(ctx context.Context, usedID int64, next *domain.RefreshToken) error {
+	return m.RotateRefreshTokenFunc(ctx, usedID, next)
+}
+
+// newTestService creates a service on top of users and sessions with in-memory
+// revocation and attempt stores, an HMAC key and no login lockout. Access tokens
+// live for an hour and refresh tokens for a day; tests override fields as needed.
+func newTestService(users *mockUserRepository, sessions *mockSessionRepository) *Service {
+	return New(users, sessions, memory.NewRevocationStore(), memory.NewAttemptStore(), NewHMACKeys("test-secret"), time.Hour, 24*time.Hour, LockoutPolicy{})
+}
+
 func TestService_Register(t *testing.T) {
 	t.Run("successful registration", func(t *testing.T) {
 		mockRepo := &mockUserRepository{
@@ -43,12 +104,14 @@ func TestService_Register(t *testing.T) {
 			},
 		}
 
-		service := New(mockRepo, "test-secret", time.Hour)
+		service := newTestService(mockRepo, &mockSessionRepository{})
 
-		token, user, err := service.Register(context.Background(), "newuser", "ValidPass123!")
+		tokens, user, err := service.Register(context.Background(), "newuser", "ValidPass123!", domain.ClientInfo{})
 
 		assert.NoError(t, err)
-		assert.NotEmpty(t, token)
+		require.NotNil(t, tokens)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEmpty(t, tokens.RefreshToken)
 		require.NotNil(t, user)
 		assert.Equal(t, int64(1), user.ID)
 		assert.Equal(t, "newuser", user.Login)
@@ -61,9 +124,9 @@ func TestService_Register(t *testing.T) {
 			},
 		}
 
-		service := New(mockRepo, "test-secret", time.Hour)
+		service := newTestService(mockRepo, &mockSessionRepository{})
 
-		_, _, err := service.Register(context.Background(), "existinguser", "ValidPass123!")
+		_, _, err := service.Register(context.Background(), "existinguser", "ValidPass123!", domain.ClientInfo{})
 
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, services.ErrUserExists))
@@ -90,9 +153,9 @@ func TestService_Register_Validation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &mockUserRepository{}
-			service := New(mockRepo, "test-secret", time.Hour)
+			service := newTestService(mockRepo, &mockSessionRepository{})
 
-			_, _, err := service.Register(context.Background(), tt.login, tt.password)
+			_, _, err := service.Register(context.Background(), tt.login, tt.password, domain.ClientInfo{})
 
 			require.Error(t, err)
 			assert.True(t, errors.Is(err, services.ErrInvalidInput))
@@ -152,14 +215,16 @@ func TestService_Login(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			service := New(tt.mockRepo, "test-secret", time.Hour)
-			token, err := service.Login(context.Background(), tt.login, tt.password)
+			service := newTestService(tt.mockRepo, &mockSessionRepository{})
+			tokens, err := service.Login(context.Background(), tt.login, tt.password, domain.ClientInfo{})
 
 			if tt.expectToken {
-				assert.NotEmpty(t, token)
 				assert.NoError(t, err)
+				require.NotNil(t, tokens)
+				assert.NotEmpty(t, tokens.AccessToken)
+				assert.NotEmpty(t, tokens.RefreshToken)
 			} else {
-				assert.Empty(t, token)
+				assert.Nil(t, tokens)
 				require.Error(t, err)
 				assert.True(t, errors.Is(err, tt.expectedErr), fmt.Sprintf("expected error %v, got %v", tt.expectedErr, err))
 			}
@@ -181,9 +246,9 @@ func TestService_Login_Validation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &mockUserRepository{}
-			service := New(mockRepo, "test-secret", time.Hour)
+			service := newTestService(mockRepo, &mockSessionRepository{})
 
-			_, err := service.Login(context.Background(), tt.login, tt.password)
+			_, err := service.Login(context.Background(), tt.login, tt.password, domain.ClientInfo{})
 
 			require.Error(t, err)
 			assert.True(t, errors.Is(err, services.ErrInvalidInput))
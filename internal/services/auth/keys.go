@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"cmp"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits is the smallest RSA key accepted for signing tokens.
+const minRSABits = 2048
+
+// Keys holds the key access tokens are signed with and every key tokens are
+// accepted from. Asymmetric keys are identified by the kid header; an HMAC
+// secret has no ID, so tokens without kid are checked against it.
+type Keys struct {
+	signing   *signingKey
+	verifying map[string]*signingKey
+}
+
+type signingKey struct {
+	id     string
+	method jwt.SigningMethod
+	sign   any // private key or secret; nil for keys that only verify
+	verify any // public key or secret
+}
+
+// NewHMACKeys returns keys that sign and verify tokens with an HS256 secret.
+func NewHMACKeys(secret string) *Keys {
+	k := hmacKey(secret)
+	return &Keys{signing: k, verifying: map[string]*signingKey{k.id: k}}
+}
+
+// LoadKeys reads the PEM-encoded private key tokens are signed with, RSA (RS256)
+// or Ed25519 (EdDSA), and the public keys tokens are also accepted from, e.g. the
+// previous key during a rotation. If secret is not empty, HS256 tokens issued
+// before switching to asymmetric keys are accepted as well.
+func LoadKeys(privateKeyFile string, publicKeyFiles []string, secret string) (*Keys, error) {
+	signing, err := loadPrivateKey(privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := &Keys{signing: signing, verifying: map[string]*signingKey{signing.id: signing}}
+	for _, file := range publicKeyFiles {
+		k, err := loadPublicKey(file)
+		if err != nil {
+			return nil, err
+		}
+		keys.verifying[k.id] = k
+	}
+	if secret != "" {
+		k := hmacKey(secret)
+		keys.verifying[k.id] = k
+	}
+
+	return keys, nil
+}
+
+// PublicKeys returns the asymmetric verification keys, to be published as a JWKS.
+func (k *Keys) PublicKeys() []domain.PublicKey {
+	var keys []domain.PublicKey
+	for _, key := range k.verifying {
+		if key.id == "" {
+			continue
+		}
+		keys = append(keys, domain.PublicKey{ID: key.id, Algorithm: key.method.Alg(), Key: key.verify})
+	}
+	slices.SortFunc(keys, func(a, b domain.PublicKey) int { return cmp.Compare(a.ID, b.ID) })
+	return keys
+}
+
+// sign signs a token with the signing key and sets its kid header.
+func (k *Keys) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signing.method, claims)
+	if k.signing.id != "" {
+		token.Header["kid"] = k.signing.id
+	}
+	return token.SignedString(k.signing.sign)
+}
+
+// keyFunc finds the key a token is verified with by its kid header. The
+// algorithm must be the one of the key, so that a public key is never used
+// as an HMAC secret.
+func (k *Keys) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.verifying[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verify, nil
+}
+
+func hmacKey(secret string) *signingKey {
+	return &signingKey{method: jwt.SigningMethodHS256, sign: []byte(secret), verify: []byte(secret)}
+}
+
+func loadPrivateKey(file string) (*signingKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var priv any
+	if block.Type == "RSA PRIVATE KEY" {
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", file, err)
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T in %s", priv, file)
+	}
+	k, err := newAsymmetricKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	k.sign = priv
+
+	return k, nil
+}
+
+func loadPublicKey(file string) (*signingKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", file, err)
+	}
+	k, err := newAsymmetricKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return k, nil
+}
+
+// newAsymmetricKey returns a verification key for pub. Its ID is derived from
+// the key itself, so the same key always gets the same kid.
+func newAsymmetricKey(pub crypto.PublicKey) (*signingKey, error) {
+	var method jwt.SigningMethod
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+		}
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, want RSA or Ed25519", pub)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+
+	return &signingKey{id: base64.RawURLEncoding.EncodeToString(sum[:]), method: method, verify: pub}, nil
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data in " + file)
+	}
+	return block, nil
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyPair writes priv as a PKCS#8 PEM file and its public key as a PKIX PEM
+// file and returns their paths.
+func writeKeyPair(t *testing.T, name string, priv crypto.Signer) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(priv.Public())
+	require.NoError(t, err)
+
+	privFile := filepath.Join(dir, name+".pem")
+	pubFile := filepath.Join(dir, name+".pub.pem")
+	require.NoError(t, os.WriteFile(privFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0o600))
+	require.NoError(t, os.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o600))
+
+	return privFile, pubFile
+}
+
+func TestKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaPriv, rsaPub := writeKeyPair(t, "rsa", rsaKey)
+	edPriv, edPub := writeKeyPair(t, "ed25519", edKey)
+
+	claims := jwt.RegisteredClaims{Subject: "1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+	verify := func(keys *Keys, token string) error {
+		_, err := jwt.ParseWithClaims(token, &jwt.RegisteredClaims{}, keys.keyFunc)
+		return err
+	}
+
+	for _, tt := range []struct {
+		name string
+		priv string
+		alg  string
+	}{
+		{"RSA", rsaPriv, "RS256"},
+		{"Ed25519", edPriv, "EdDSA"},
+	} {
+		t.Run(tt.name+" signs tokens with kid", func(t *testing.T) {
+			keys, err := LoadKeys(tt.priv, nil, "")
+			require.NoError(t, err)
+
+			token, err := keys.sign(claims)
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.alg, parsed.Method.Alg())
+			require.Len(t, keys.PublicKeys(), 1)
+			assert.Equal(t, keys.PublicKeys()[0].ID, parsed.Header["kid"])
+			assert.NoError(t, verify(keys, token))
+		})
+	}
+
+	t.Run("accepts tokens of the previous key during rotation", func(t *testing.T) {
+		oldKeys, err := LoadKeys(rsaPriv, nil, "")
+		require.NoError(t, err)
+		token, err := oldKeys.sign(claims)
+		require.NoError(t, err)
+
+		rotated, err := LoadKeys(edPriv, []string{rsaPub}, "")
+		require.NoError(t, err)
+		assert.NoError(t, verify(rotated, token))
+		assert.Len(t, rotated.PublicKeys(), 2)
+
+		withoutOld, err := LoadKeys(edPriv, nil, "")
+		require.NoError(t, err)
+		assert.Error(t, verify(withoutOld, token))
+	})
+
+	t.Run("the same key gets the same kid", func(t *testing.T) {
+		signer, err := LoadKeys(edPriv, nil, "")
+		require.NoError(t, err)
+		verifier, err := LoadKeys(rsaPriv, []string{edPub}, "")
+		require.NoError(t, err)
+
+		ids := func(keys []domain.PublicKey) []string {
+			var ids []string
+			for _, k := range keys {
+				ids = append(ids, k.ID)
+			}
+			return ids
+		}
+		assert.Subset(t, ids(verifier.PublicKeys()), ids(signer.PublicKeys()))
+	})
+
+	t.Run("accepts HS256 tokens only when the secret is kept", func(t *testing.T) {
+		legacy, err := NewHMACKeys("test-secret").sign(claims)
+		require.NoError(t, err)
+
+		withSecret, err := LoadKeys(rsaPriv, nil, "test-secret")
+		require.NoError(t, err)
+		assert.NoError(t, verify(withSecret, legacy))
+		assert.Len(t, withSecret.PublicKeys(), 1, "the secret must not be published")
+
+		withoutSecret, err := LoadKeys(rsaPriv, nil, "")
+		require.NoError(t, err)
+		assert.Error(t, verify(withoutSecret, legacy))
+	})
+
+	t.Run("rejects a token whose algorithm does not match the key", func(t *testing.T) {
+		keys, err := LoadKeys(rsaPriv, nil, "")
+		require.NoError(t, err)
+		pubDER, err := x509.MarshalPKIXPublicKey(rsaKey.Public())
+		require.NoError(t, err)
+
+		// A forged token "signed" with the public key as an HMAC secret
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		forged.Header["kid"] = keys.PublicKeys()[0].ID
+		token, err := forged.SignedString(pubDER)
+		require.NoError(t, err)
+
+		assert.Error(t, verify(keys, token))
+	})
+
+	t.Run("rejects invalid key files", func(t *testing.T) {
+		_, err := LoadKeys(filepath.Join(t.TempDir(), "missing.pem"), nil, "")
+		assert.Error(t, err)
+
+		_, err = LoadKeys(rsaPub, nil, "")
+		assert.Error(t, err, "a public key cannot sign")
+
+		weak, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+		weakPriv, _ := writeKeyPair(t, "weak", weak)
+		_, err = LoadKeys(weakPriv, nil, "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+)
+
+// LockoutPolicy limits failed logins. Once a login or an IP address reaches its
+// number of failures, it is locked out for Base, and each further failure doubles
+// the lockout up to Max. Failures are forgotten after Window without new ones.
+type LockoutPolicy struct {
+	MaxFailures   int // per login; zero disables the limit
+	IPMaxFailures int // per IP address; zero disables the limit
+	Base          time.Duration
+	Max           time.Duration
+	Window        time.Duration
+}
+
+// lockoutKey is a key failed logins are counted under, with the limit for it.
+// Failures of a login are reset by a successful login; failures of an IP address
+// are kept, or one valid account would let an attacker reset the counter between
+// guesses at others.
+type lockoutKey struct {
+	key            string
+	maxFailures    int
+	resetOnSuccess bool
+}
+
+// loginAttempt is a login recorded as failed under its keys before the password
+// is checked, with the failures each key had before it.
+type loginAttempt struct {
+	keys []lockoutKey
+	prev []domain.Attempts
+}
+
+// lockoutKeys returns the keys failed logins with login from client are counted under.
+func (s *Service) lockoutKeys(login string, client domain.ClientInfo) []lockoutKey {
+	var keys []lockoutKey
+	if s.lockout.MaxFailures > 0 {
+		keys = append(keys, lockoutKey{key: "login:" + login, maxFailures: s.lockout.MaxFailures, resetOnSuccess: true})
+	}
+	if s.lockout.IPMaxFailures > 0 && client.IP != "" {
+		keys = append(keys, lockoutKey{key: "ip:" + client.IP, maxFailures: s.lockout.IPMaxFailures})
+	}
+	return keys
+}
+
+// startAttempt records a login with login from client as failed before the password
+// is checked. Recording first makes concurrent guesses count one after another, so
+// they cannot all slip through before the first failure is seen. If any key was
+// locked out already, the attempt is reverted and a RetryAfterError wrapping
+// services.ErrTooManyRequests is returned; locked out guesses cost no bcrypt work.
+func (s *Service) startAttempt(ctx context.Context, login string, client domain.ClientInfo) (*loginAttempt, error) {
+	now := s.now()
+	attempt := &loginAttempt{}
+
+	var wait time.Duration
+	for _, k := range s.lockoutKeys(login, client) {
+		prev, err := s.attempts.RecordFailure(ctx, k.key, now, now.Add(-s.lockout.Window))
+		if err != nil {
+			return nil, fmt.Errorf("failed to record login attempt: %w", err)
+		}
+		attempt.keys = append(attempt.keys, k)
+		attempt.prev = append(attempt.prev, prev)
+
+		if d := prev.LastFailedAt.Add(s.lockoutDuration(prev.Failures, k.maxFailures)).Sub(now); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		if err := s.revertAttempt(ctx, attempt, false); err != nil {
+			return nil, err
+		}
+		return nil, &services.RetryAfterError{Err: services.ErrTooManyRequests, After: wait}
+	}
+
+	return attempt, nil
+}
+
+// revertAttempt undoes a login attempt that did not fail. After a successful
+// login, the failures of keys reset on success are forgotten altogether.
+func (s *Service) revertAttempt(ctx context.Context, attempt *loginAttempt, succeeded bool) error {
+	for i, k := range attempt.keys {
+		if succeeded && k.resetOnSuccess {
+			if err := s.attempts.ResetFailures(ctx, k.key); err != nil {
+				return fmt.Errorf("failed to reset failed logins: %w", err)
+			}
+			continue
+		}
+		if err := s.attempts.RevertFailure(ctx, k.key, attempt.prev[i]); err != nil {
+			return fmt.Errorf("failed to revert login attempt: %w", err)
+		}
+	}
+	return nil
+}
+
+// abortAttempt reverts a login attempt that failed with err before the credentials
+// were checked, so that outages do not lock users out, and returns err.
+func (s *Service) abortAttempt(ctx context.Context, attempt *loginAttempt, err error) error {
+	if revertErr := s.revertAttempt(ctx, attempt, false); revertErr != nil {
+		return errors.Join(err, revertErr)
+	}
+	return err
+}
+
+// lockoutDuration returns how long a key is locked out after failures, given its limit.
+func (s *Service) lockoutDuration(failures, maxFailures int) time.Duration {
+	if failures < maxFailures {
+		return 0
+	}
+	d := s.lockout.Base
+	for i := maxFailures; i < failures && d < s.lockout.Max; i++ {
+		d *= 2
+	}
+	return min(d, s.lockout.Max)
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestService_Login_Lockout(t *testing.T) {
+	ctx := context.Background()
+	hash, err := bcrypt.GenerateFromPassword([]byte("ValidPass123!"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	var lookups int
+	users := &mockUserRepository{
+		FindByLoginFunc: func(ctx context.Context, login string) (*domain.User, error) {
+			lookups++
+			if login != "testuser" {
+				return nil, storage.ErrUserNotFound
+			}
+			return &domain.User{ID: 1, Login: login, PasswordHash: string(hash)}, nil
+		},
+	}
+	policy := LockoutPolicy{MaxFailures: 2, IPMaxFailures: 5, Base: 30 * time.Second, Max: time.Minute, Window: time.Hour}
+	newService := func() (*Service, *time.Time) {
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		s := newTestService(users, &mockSessionRepository{})
+		s.lockout = policy
+		s.now = func() time.Time { return now }
+		return s, &now
+	}
+	client := domain.ClientInfo{IP: "192.0.2.1"}
+
+	assertLocked := func(t *testing.T, err error, after time.Duration) {
+		t.Helper()
+		var retry *services.RetryAfterError
+		require.True(t, errors.As(err, &retry), "expected a RetryAfterError, got %v", err)
+		assert.ErrorIs(t, err, services.ErrTooManyRequests)
+		assert.Equal(t, after, retry.After)
+	}
+
+	t.Run("locks the login out with a growing delay", func(t *testing.T) {
+		s, now := newService()
+
+		for range policy.MaxFailures {
+			_, err := s.Login(ctx, "testuser", "WrongPass123!", client)
+			assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+		}
+
+		// Even the right password is refused without checking it while locked out
+		lookups = 0
+		_, err := s.Login(ctx, "testuser", "ValidPass123!", client)
+		assertLocked(t, err, 30*time.Second)
+		assert.Zero(t, lookups)
+
+		*now = now.Add(30 * time.Second)
+		_, err = s.Login(ctx, "testuser", "WrongPass123!", client)
+		assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+
+		_, err = s.Login(ctx, "testuser", "ValidPass123!", client)
+		assertLocked(t, err, time.Minute)
+
+		// The delay doubles with every failure, but no further than Max
+		*now = now.Add(time.Minute)
+		_, err = s.Login(ctx, "testuser", "WrongPass123!", client)
+		assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+
+		_, err = s.Login(ctx, "testuser", "ValidPass123!", client)
+		assertLocked(t, err, time.Minute)
+	})
+
+	t.Run("success resets the login failures", func(t *testing.T) {
+		s, _ := newService()
+
+		_, err := s.Login(ctx, "testuser", "WrongPass123!", client)
+		assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+
+		_, err = s.Login(ctx, "testuser", "ValidPass123!", client)
+		require.NoError(t, err)
+
+		_, err = s.Login(ctx, "testuser", "WrongPass123!", client)
+		assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+
+		_, err = s.Login(ctx, "testuser", "ValidPass123!", client)
+		assert.NoError(t, err)
+	})
+
+	t.Run("locks the IP address out across logins", func(t *testing.T) {
+		s, _ := newService()
+
+		for i := range policy.IPMaxFailures {
+			_, err := s.Login(ctx, "unknown"+string(rune('a'+i)), "WrongPass123!", client)
+			assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+		}
+
+		_, err := s.Login(ctx, "testuser", "ValidPass123!", client)
+		assertLocked(t, err, 30*time.Second)
+
+		_, err = s.Login(ctx, "testuser", "ValidPass123!", domain.ClientInfo{IP: "192.0.2.2"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("infrastructure errors do not count as failures", func(t *testing.T) {
+		s, _ := newService()
+		dbErr := errors.New("connection refused")
+		s.userRepo = &mockUserRepository{
+			FindByLoginFunc: func(ctx context.Context, login string) (*domain.User, error) {
+				return nil, dbErr
+			},
+		}
+
+		for range policy.MaxFailures + 1 {
+			_, err := s.Login(ctx, "testuser", "ValidPass123!", client)
+			assert.ErrorIs(t, err, dbErr)
+		}
+
+		s.userRepo = users
+		_, err := s.Login(ctx, "testuser", "WrongPass123!", client)
+		assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+	})
+
+	t.Run("failures are forgotten after the window", func(t *testing.T) {
+		s, now := newService()
+
+		for range policy.MaxFailures {
+			_, err := s.Login(ctx, "testuser", "WrongPass123!", client)
+			assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+		}
+
+		*now = now.Add(policy.Window + time.Second)
+		_, err := s.Login(ctx, "testuser", "WrongPass123!", client)
+		assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+	})
+}
+
+func TestService_Login_LockoutConcurrent(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("ValidPass123!"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	var checked atomic.Int32
+	users := &mockUserRepository{
+		FindByLoginFunc: func(ctx context.Context, login string) (*domain.User, error) {
+			checked.Add(1)
+			return &domain.User{ID: 1, Login: login, PasswordHash: string(hash)}, nil
+		},
+	}
+	s := newTestService(users, &mockSessionRepository{})
+	s.lockout = LockoutPolicy{MaxFailures: 3, Base: time.Minute, Max: time.Hour, Window: time.Hour}
+
+	// Parallel guesses must not all pass the check before the first failure is counted
+	const guesses = 20
+	var (
+		wg     sync.WaitGroup
+		locked atomic.Int32
+	)
+	for range guesses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Login(context.Background(), "testuser", "WrongPass123!", domain.ClientInfo{IP: "192.0.2.1"})
+			if errors.Is(err, services.ErrTooManyRequests) {
+				locked.Add(1)
+			} else {
+				assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(3), checked.Load())
+	assert.Equal(t, int32(guesses-3), locked.Load())
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+)
+
+// Logout ends the session of userID the access token belongs to: the token is
+// revoked until it expires, and so are the session and its refresh tokens. Tokens
+// issued before sessions existed name their session by refreshToken, if given.
+// Unknown refresh tokens and those of other users are ignored, so that logging
+// out twice is harmless.
+func (s *Service) Logout(ctx context.Context, userID int64, accessToken, refreshToken string) error {
+	claims, err := s.parseClaims(accessToken)
+	if err != nil {
+		return fmt.Errorf("%w: %v", services.ErrUnauthorized, err)
+	}
+
+	// Tokens issued before jti was introduced cannot be revoked one by one
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.revocations.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+	}
+
+	sessionID := claims.SessionID
+	if sessionID == "" && refreshToken != "" {
+		t, err := s.sessions.FindRefreshToken(ctx, hashToken(refreshToken))
+		if err != nil && !errors.Is(err, storage.ErrRefreshTokenNotFound) {
+			return fmt.Errorf("failed to find refresh token: %w", err)
+		}
+		if err == nil {
+			sessionID = t.FamilyID
+		}
+	}
+	if sessionID == "" {
+		return nil
+	}
+
+	// RevokeSession only matches sessions of userID
+	err = s.sessions.RevokeSession(ctx, userID, sessionID)
+	if err != nil && !errors.Is(err, storage.ErrSessionNotFound) {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+// LogoutAll ends every session of userID: all sessions and refresh tokens are
+// revoked, and the token version is bumped, which rejects all access tokens
+// issued so far, including those issued before sessions existed.
+func (s *Service) LogoutAll(ctx context.Context, userID int64) error {
+	// Sessions go first, so that no refresh token is left to get a token with the new version
+	if err := s.sessions.RevokeUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := s.userRepo.IncrementTokenVersion(ctx, userID); err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return services.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to bump token version: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+	"github.com/felix-kado/vk-test-task/internal/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ParseToken(t *testing.T) {
+	user := &domain.User{ID: 1, Login: "testuser", TokenVersion: 2}
+	users := &mockUserRepository{
+		FindUserByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			u := *user
+			return &u, nil
+		},
+	}
+	sessions := &mockSessionRepository{
+		TouchSessionFunc: func(ctx context.Context, id string) error {
+			if id == "revoked" {
+				return storage.ErrSessionNotFound
+			}
+			return nil
+		},
+	}
+	s := newTestService(users, sessions)
+
+	t.Run("valid token", func(t *testing.T) {
+		token, err := s.generateToken(user, "", time.Now())
+		require.NoError(t, err)
+
+		u, err := s.ParseToken(context.Background(), token)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), u.ID)
+	})
+
+	t.Run("tokens get distinct IDs", func(t *testing.T) {
+		now := time.Now()
+		first, err := s.generateToken(user, "", now)
+		require.NoError(t, err)
+		second, err := s.generateToken(user, "", now)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		token, err := s.generateToken(user, "", time.Now())
+		require.NoError(t, err)
+		require.NoError(t, s.Logout(context.Background(), 1, token, ""))
+
+		_, err = s.ParseToken(context.Background(), token)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("token of an active session", func(t *testing.T) {
+		token, err := s.generateToken(user, "active", time.Now())
+		require.NoError(t, err)
+
+		_, err = s.ParseToken(context.Background(), token)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("token of a revoked session", func(t *testing.T) {
+		token, err := s.generateToken(user, "revoked", time.Now())
+		require.NoError(t, err)
+
+		_, err = s.ParseToken(context.Background(), token)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("token of an older version", func(t *testing.T) {
+		token, err := s.generateToken(&domain.User{ID: 1, TokenVersion: 1}, "", time.Now())
+		require.NoError(t, err)
+
+		_, err = s.ParseToken(context.Background(), token)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		token, err := s.generateToken(user, "", time.Now().Add(-2*time.Hour))
+		require.NoError(t, err)
+
+		_, err = s.ParseToken(context.Background(), token)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestService_Logout(t *testing.T) {
+	user := &domain.User{ID: 1, Login: "testuser"}
+
+	tests := []struct {
+		name        string
+		sessionID   string // sid of the access token
+		refresh     string
+		stored      *domain.RefreshToken
+		revokeErr   error
+		wantRevoked string
+	}{
+		{
+			name:        "revokes the session of the access token",
+			sessionID:   "session",
+			wantRevoked: "session",
+		},
+		{
+			name:        "revokes the session of the refresh token for older access tokens",
+			refresh:     "refresh",
+			stored:      &domain.RefreshToken{ID: 5, UserID: 1, FamilyID: "family"},
+			wantRevoked: "family",
+		},
+		{
+			name:        "ignores a session that is revoked or of another user",
+			refresh:     "refresh",
+			stored:      &domain.RefreshToken{ID: 5, UserID: 2, FamilyID: "family"},
+			revokeErr:   storage.ErrSessionNotFound,
+			wantRevoked: "family",
+		},
+		{
+			name:    "ignores an unknown refresh token",
+			refresh: "unknown",
+		},
+		{
+			name: "without a session",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var revoked string
+			repo := &mockSessionRepository{
+				FindRefreshTokenFunc: func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+					if tt.stored == nil {
+						return nil, storage.ErrRefreshTokenNotFound
+					}
+					return tt.stored, nil
+				},
+				RevokeSessionFunc: func(ctx context.Context, userID int64, id string) error {
+					assert.Equal(t, int64(1), userID)
+					revoked = id
+					return tt.revokeErr
+				},
+			}
+			revocations := memory.NewRevocationStore()
+			s := newTestService(&mockUserRepository{}, repo)
+			s.revocations = revocations
+			token, err := s.generateToken(user, tt.sessionID, time.Now())
+			require.NoError(t, err)
+			claims, err := s.parseClaims(token)
+			require.NoError(t, err)
+
+			err = s.Logout(context.Background(), 1, token, tt.refresh)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRevoked, revoked)
+			isRevoked, err := revocations.IsTokenRevoked(context.Background(), claims.ID)
+			require.NoError(t, err)
+			assert.True(t, isRevoked)
+		})
+	}
+
+	t.Run("invalid access token", func(t *testing.T) {
+		s := newTestService(&mockUserRepository{}, &mockSessionRepository{})
+
+		err := s.Logout(context.Background(), 1, "not-a-token", "")
+
+		assert.ErrorIs(t, err, services.ErrUnauthorized)
+	})
+}
+
+func TestService_LogoutAll(t *testing.T) {
+	t.Run("revokes sessions and bumps the token version", func(t *testing.T) {
+		var calls []string
+		users := &mockUserRepository{
+			IncrementTokenVersionFunc: func(ctx context.Context, id int64) error {
+				assert.Equal(t, int64(1), id)
+				calls = append(calls, "version")
+				return nil
+			},
+		}
+		repo := &mockSessionRepository{
+			RevokeUserSessionsFunc: func(ctx context.Context, userID int64) error {
+				assert.Equal(t, int64(1), userID)
+				calls = append(calls, "sessions")
+				return nil
+			},
+		}
+		s := newTestService(users, repo)
+
+		err := s.LogoutAll(context.Background(), 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sessions", "version"}, calls)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		users := &mockUserRepository{
+			IncrementTokenVersionFunc: func(ctx context.Context, id int64) error {
+				return storage.ErrUserNotFound
+			},
+		}
+		repo := &mockSessionRepository{
+			RevokeUserSessionsFunc: func(ctx context.Context, userID int64) error { return nil },
+		}
+		s := newTestService(users, repo)
+
+		err := s.LogoutAll(context.Background(), 1)
+
+		assert.ErrorIs(t, err, services.ErrUserNotFound)
+	})
+
+	t.Run("storage failure", func(t *testing.T) {
+		repo := &mockSessionRepository{
+			RevokeUserSessionsFunc: func(ctx context.Context, userID int64) error {
+				return errors.New("db is down")
+			},
+		}
+		s := newTestService(&mockUserRepository{}, repo)
+
+		err := s.LogoutAll(context.Background(), 1)
+
+		assert.Error(t, err)
+	})
+}
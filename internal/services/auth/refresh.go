@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+)
+
+// Refresh exchanges a refresh token for a new token pair of the same session. Each
+// refresh token can be used once; presenting a used one again means it has leaked,
+// so the whole session, including the token issued in its place, is revoked.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, fmt.Errorf("%w: refresh_token is required", services.ErrInvalidInput)
+	}
+
+	t, err := s.sessions.FindRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrRefreshTokenNotFound) {
+			return nil, services.ErrUnauthorized
+		}
+		return nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+	if t.RevokedAt != nil {
+		return nil, services.ErrUnauthorized
+	}
+	if t.UsedAt != nil {
+		return nil, s.revokeFamily(ctx, t)
+	}
+	if !s.now().Before(t.ExpiresAt) {
+		return nil, services.ErrUnauthorized
+	}
+
+	u, err := s.userRepo.FindUserByID(ctx, t.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, services.ErrUnauthorized
+		}
+		return nil, fmt.Errorf("failed to find user by id: %w", err)
+	}
+
+	return s.issueTokens(ctx, u, t, domain.ClientInfo{})
+}
+
+// revokeFamily revokes the session of a reused refresh token and returns the
+// error to report to the caller.
+func (s *Service) revokeFamily(ctx context.Context, t *domain.RefreshToken) error {
+	err := s.sessions.RevokeSession(ctx, t.UserID, t.FamilyID)
+	if err != nil && !errors.Is(err, storage.ErrSessionNotFound) {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return services.ErrUnauthorized
+}
+
+// issueTokens creates an access token and a refresh token for u. If used is nil,
+// a new session is started for client; otherwise the refresh token replaces used
+// in its session, and used is marked used in the same step.
+func (s *Service) issueTokens(ctx context.Context, u *domain.User, used *domain.RefreshToken, client domain.ClientInfo) (*domain.TokenPair, error) {
+	now := s.now()
+
+	refresh := rand.Text()
+	t := &domain.RefreshToken{
+		UserID:    u.ID,
+		TokenHash: hashToken(refresh),
+		ExpiresAt: now.Add(s.refreshTTL),
+	}
+	var err error
+	if used != nil {
+		t.FamilyID = used.FamilyID
+		err = s.sessions.RotateRefreshToken(ctx, used.ID, t)
+		if errors.Is(err, storage.ErrRefreshTokenUsed) {
+			// Another request has just used or revoked the same token
+			return nil, s.revokeFamily(ctx, used)
+		}
+	} else {
+		session := &domain.Session{UserID: u.ID, UserAgent: client.UserAgent, IP: client.IP}
+		err = s.sessions.CreateSession(ctx, session, t)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	access, err := s.generateToken(u, t.FamilyID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &domain.TokenPair{
+		AccessToken:      access,
+		AccessExpiresAt:  now.Add(s.tokenTTL),
+		RefreshToken:     refresh,
+		RefreshExpiresAt: t.ExpiresAt,
+	}, nil
+}
+
+// hashToken returns the hex-encoded SHA-256 hash a refresh token is stored under.
+// Refresh tokens are random, so a fast hash without salt is enough.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
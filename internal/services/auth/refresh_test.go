@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Refresh(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	users := &mockUserRepository{
+		FindUserByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			return &domain.User{ID: id, Login: "testuser"}, nil
+		},
+	}
+	stored := func() *domain.RefreshToken {
+		return &domain.RefreshToken{ID: 7, UserID: 1, FamilyID: "family", TokenHash: hashToken("old"), ExpiresAt: later}
+	}
+
+	newService := func(repo *mockSessionRepository) *Service {
+		s := newTestService(users, repo)
+		s.tokenTTL = 15 * time.Minute
+		s.now = func() time.Time { return now }
+		return s
+	}
+
+	t.Run("rotates the token within its session", func(t *testing.T) {
+		var rotated *domain.RefreshToken
+		repo := &mockSessionRepository{
+			FindRefreshTokenFunc: func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+				assert.Equal(t, hashToken("old"), tokenHash)
+				return stored(), nil
+			},
+			RotateRefreshTokenFunc: func(ctx context.Context, usedID int64, next *domain.RefreshToken) error {
+				assert.Equal(t, int64(7), usedID)
+				rotated = next
+				return nil
+			},
+		}
+
+		tokens, err := newService(repo).Refresh(context.Background(), "old")
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.Equal(t, now.Add(15*time.Minute), tokens.AccessExpiresAt)
+		assert.NotEqual(t, "old", tokens.RefreshToken)
+		assert.Equal(t, now.Add(24*time.Hour), tokens.RefreshExpiresAt)
+		require.NotNil(t, rotated)
+		assert.Equal(t, "family", rotated.FamilyID)
+		assert.Equal(t, int64(1), rotated.UserID)
+		assert.Equal(t, hashToken(tokens.RefreshToken), rotated.TokenHash)
+		// The token was issued at a fixed time in the past, so its expiry is not checked
+		claims := &tokenClaims{}
+		_, _, err = jwt.NewParser().ParseUnverified(tokens.AccessToken, claims)
+		require.NoError(t, err)
+		assert.Equal(t, "family", claims.SessionID)
+	})
+
+	t.Run("reused token revokes the session", func(t *testing.T) {
+		var revoked string
+		repo := &mockSessionRepository{
+			FindRefreshTokenFunc: func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+				tok := stored()
+				tok.UsedAt = &now
+				return tok, nil
+			},
+			RevokeSessionFunc: func(ctx context.Context, userID int64, id string) error {
+				assert.Equal(t, int64(1), userID)
+				revoked = id
+				return nil
+			},
+		}
+
+		_, err := newService(repo).Refresh(context.Background(), "old")
+
+		assert.ErrorIs(t, err, services.ErrUnauthorized)
+		assert.Equal(t, "family", revoked)
+	})
+
+	t.Run("concurrent use revokes the session", func(t *testing.T) {
+		var revoked string
+		repo := &mockSessionRepository{
+			FindRefreshTokenFunc: func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+				return stored(), nil
+			},
+			RotateRefreshTokenFunc: func(ctx context.Context, usedID int64, next *domain.RefreshToken) error {
+				return storage.ErrRefreshTokenUsed
+			},
+			RevokeSessionFunc: func(ctx context.Context, userID int64, id string) error {
+				assert.Equal(t, int64(1), userID)
+				revoked = id
+				return nil
+			},
+		}
+
+		_, err := newService(repo).Refresh(context.Background(), "old")
+
+		assert.ErrorIs(t, err, services.ErrUnauthorized)
+		assert.Equal(t, "family", revoked)
+	})
+
+	tests := []struct {
+		name  string
+		token string
+		find  func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+		want  error
+	}{
+		{
+			name:  "empty token",
+			token: "",
+			want:  services.ErrInvalidInput,
+		},
+		{
+			name:  "unknown token",
+			token: "unknown",
+			find: func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+				return nil, storage.ErrRefreshTokenNotFound
+			},
+			want: services.ErrUnauthorized,
+		},
+		{
+			name:  "revoked token",
+			token: "old",
+			find: func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+				tok := stored()
+				tok.RevokedAt = &now
+				return tok, nil
+			},
+			want: services.ErrUnauthorized,
+		},
+		{
+			name:  "expired token",
+			token: "old",
+			find: func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+				tok := stored()
+				tok.ExpiresAt = now
+				return tok, nil
+			},
+			want: services.ErrUnauthorized,
+		},
+		{
+			name:  "storage failure",
+			token: "old",
+			find: func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+				return nil, errors.New("db is down")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockSessionRepository{FindRefreshTokenFunc: tt.find}
+
+			tokens, err := newService(repo).Refresh(context.Background(), tt.token)
+
+			require.Error(t, err)
+			assert.Nil(t, tokens)
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+			} else {
+				assert.NotErrorIs(t, err, services.ErrUnauthorized)
+			}
+		})
+	}
+}
+
+func TestService_IssueTokens_NewSession(t *testing.T) {
+	var session *domain.Session
+	repo := &mockSessionRepository{
+		CreateSessionFunc: func(ctx context.Context, sess *domain.Session, t *domain.RefreshToken) error {
+			sess.ID = "new-session"
+			t.FamilyID = sess.ID
+			session = sess
+			return nil
+		},
+	}
+	s := newTestService(&mockUserRepository{}, repo)
+
+	tokens, err := s.issueTokens(context.Background(), &domain.User{ID: 3}, nil, domain.ClientInfo{UserAgent: "curl/8.0", IP: "192.0.2.1"})
+
+	require.NoError(t, err)
+	require.NotNil(t, session)
+	assert.Equal(t, int64(3), session.UserID)
+	assert.Equal(t, "curl/8.0", session.UserAgent)
+	assert.Equal(t, "192.0.2.1", session.IP)
+	claims, err := s.parseClaims(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "new-session", claims.SessionID)
+}
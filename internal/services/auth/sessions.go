@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+)
+
+// ListSessions returns the active sessions of userID, most recently used first.
+func (s *Service) ListSessions(ctx context.Context, userID int64) ([]domain.Session, error) {
+	sessions, err := s.sessions.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession logs userID out of one of their sessions. Access tokens of the
+// session are rejected from then on and its refresh token stops working.
+func (s *Service) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	if err := s.sessions.RevokeSession(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			return services.ErrSessionNotFound
+		}
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ListSessions(t *testing.T) {
+	want := []domain.Session{{ID: "a", UserID: 1, UserAgent: "curl/8.0", IP: "192.0.2.1"}}
+	repo := &mockSessionRepository{
+		ListSessionsFunc: func(ctx context.Context, userID int64) ([]domain.Session, error) {
+			assert.Equal(t, int64(1), userID)
+			return want, nil
+		},
+	}
+	s := newTestService(&mockUserRepository{}, repo)
+
+	sessions, err := s.ListSessions(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, want, sessions)
+}
+
+func TestService_RevokeSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoErr error
+		wantErr error
+	}{
+		{"revoked", nil, nil},
+		{"not found", storage.ErrSessionNotFound, services.ErrSessionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockSessionRepository{
+				RevokeSessionFunc: func(ctx context.Context, userID int64, id string) error {
+					assert.Equal(t, int64(1), userID)
+					assert.Equal(t, "a", id)
+					return tt.repoErr
+				},
+			}
+			s := newTestService(&mockUserRepository{}, repo)
+
+			err := s.RevokeSession(context.Background(), 1, "a")
+
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
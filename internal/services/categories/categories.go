@@ -0,0 +1,54 @@
+package categories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// CategoryRepository defines the interface for category storage.
+type CategoryRepository interface {
+	ListCategories(ctx context.Context) ([]domain.Category, error)
+}
+
+// Service provides category-related operations.
+type Service struct {
+	categoryRepo CategoryRepository
+}
+
+// New creates a new category service.
+func New(categoryRepo CategoryRepository) *Service {
+	return &Service{categoryRepo: categoryRepo}
+}
+
+// GetTree returns the category tree as a list of top-level categories with nested children.
+func (s *Service) GetTree(ctx context.Context) ([]*domain.Category, error) {
+	categories, err := s.categoryRepo.ListCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("categoryRepo.ListCategories: %w", err)
+	}
+	return buildTree(categories), nil
+}
+
+// buildTree links a flat list of categories into a tree, preserving the input order
+// among siblings. Categories whose parent is missing are treated as roots.
+func buildTree(categories []domain.Category) []*domain.Category {
+	nodes := make(map[int64]*domain.Category, len(categories))
+	for i := range categories {
+		nodes[categories[i].ID] = &categories[i]
+	}
+
+	roots := make([]*domain.Category, 0)
+	for i := range categories {
+		node := &categories[i]
+		if node.ParentID != nil {
+			if parent, ok := nodes[*node.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
@@ -0,0 +1,80 @@
+package categories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockCategoryRepository is a mock implementation of CategoryRepository for testing.
+type mockCategoryRepository struct {
+	ListCategoriesFunc func(ctx context.Context) ([]domain.Category, error)
+}
+
+func (m *mockCategoryRepository) ListCategories(ctx context.Context) ([]domain.Category, error) {
+	return m.ListCategoriesFunc(ctx)
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func TestService_GetTree(t *testing.T) {
+	t.Run("builds nested tree", func(t *testing.T) {
+		mockRepo := &mockCategoryRepository{
+			ListCategoriesFunc: func(ctx context.Context) ([]domain.Category, error) {
+				return []domain.Category{
+					{ID: 3, ParentID: int64Ptr(1), Name: "Bicycles", Slug: "bicycles"},
+					{ID: 2, ParentID: int64Ptr(1), Name: "Cars", Slug: "cars"},
+					{ID: 4, Name: "Other", Slug: "other"},
+					{ID: 1, Name: "Transport", Slug: "transport"},
+					{ID: 5, ParentID: int64Ptr(2), Name: "Electric cars", Slug: "electric-cars"},
+				}, nil
+			},
+		}
+		service := New(mockRepo)
+
+		tree, err := service.GetTree(context.Background())
+
+		require.NoError(t, err)
+		require.Len(t, tree, 2)
+		assert.Equal(t, "other", tree[0].Slug)
+		assert.Equal(t, "transport", tree[1].Slug)
+		require.Len(t, tree[1].Children, 2)
+		assert.Equal(t, "bicycles", tree[1].Children[0].Slug)
+		assert.Equal(t, "cars", tree[1].Children[1].Slug)
+		require.Len(t, tree[1].Children[1].Children, 1)
+		assert.Equal(t, "electric-cars", tree[1].Children[1].Children[0].Slug)
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		mockRepo := &mockCategoryRepository{
+			ListCategoriesFunc: func(ctx context.Context) ([]domain.Category, error) {
+				return nil, nil
+			},
+		}
+		service := New(mockRepo)
+
+		tree, err := service.GetTree(context.Background())
+
+		require.NoError(t, err)
+		assert.Empty(t, tree)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := &mockCategoryRepository{
+			ListCategoriesFunc: func(ctx context.Context) ([]domain.Category, error) {
+				return nil, errors.New("db error")
+			},
+		}
+		service := New(mockRepo)
+
+		_, err := service.GetTree(context.Background())
+
+		assert.ErrorContains(t, err, "db error")
+	})
+}
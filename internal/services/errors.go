@@ -1,6 +1,9 @@
 package services
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // Service layer errors - business logic focused
 var (
@@ -9,14 +12,31 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUnauthorized       = errors.New("unauthorized")
 	ErrForbidden          = errors.New("forbidden")
-	
+
 	// Resource errors
-	ErrAdNotFound = errors.New("ad not found")
-	
+	ErrAdNotFound      = errors.New("ad not found")
+	ErrImageNotFound   = errors.New("image not found")
+	ErrSessionNotFound = errors.New("session not found")
+
 	// Input validation errors
-	ErrInvalidInput = errors.New("invalid input")
-	
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrPayloadTooLarge = errors.New("payload too large")
+
 	// Conflict errors
 	ErrUserExists = errors.New("user already exists")
 	ErrConflict   = errors.New("resource conflict")
+
+	// Rate limiting errors
+	ErrTooManyRequests = errors.New("too many requests")
 )
+
+// RetryAfterError reports that an action is not allowed yet. It wraps the
+// cause, ErrConflict or ErrTooManyRequests, and tells how long the caller should wait.
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryAfterError) Error() string { return e.Err.Error() }
+
+func (e *RetryAfterError) Unwrap() error { return e.Err }
@@ -0,0 +1,128 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register decoders for image.DecodeConfig
+	_ "image/png"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	_ "golang.org/x/image/webp"
+)
+
+// BlobStore defines the interface for storing uploaded images.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	URL(key string) string
+}
+
+// ImageRepository defines the interface for image metadata storage.
+type ImageRepository interface {
+	CreateImage(ctx context.Context, img *domain.UploadedImage) error
+	ClaimPendingImages(ctx context.Context, limit int) ([]domain.UploadedImage, error)
+	SetImageVariants(ctx context.Context, id int64, variants []domain.ImageVariant) error
+	FailImage(ctx context.Context, id int64, maxAttempts int) error
+}
+
+// UploadPolicy limits the images that may be uploaded.
+type UploadPolicy struct {
+	MaxSize   int64 // maximum file size in bytes
+	MaxWidth  int   // maximum width in pixels
+	MaxHeight int   // maximum height in pixels
+}
+
+// Service provides image uploads.
+type Service struct {
+	store     BlobStore
+	imageRepo ImageRepository
+	policy    UploadPolicy
+	now       func() time.Time
+}
+
+// New creates a new image service.
+func New(store BlobStore, imageRepo ImageRepository, policy UploadPolicy) *Service {
+	return &Service{store: store, imageRepo: imageRepo, policy: policy, now: time.Now}
+}
+
+// extensions maps supported content types to the file extension of stored images.
+var extensions = map[string]string{
+	domain.ImageTypeJPEG: ".jpg",
+	domain.ImageTypePNG:  ".png",
+	domain.ImageTypeWebP: ".webp",
+}
+
+// Upload validates an image uploaded by userID and stores it under a new random key.
+// The format is detected from the content, ignoring any client-supplied type.
+// Resized variants are generated later by the VariantWorker.
+func (s *Service) Upload(ctx context.Context, userID int64, r io.Reader) (*domain.UploadedImage, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.policy.MaxSize+1))
+	if err != nil {
+		// The handler caps the whole request, which can cut the image off before MaxSize
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, fmt.Errorf("%w: request cannot exceed %d bytes", services.ErrPayloadTooLarge, maxBytesErr.Limit)
+		}
+		return nil, fmt.Errorf("images.Upload: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: image is empty", services.ErrInvalidInput)
+	}
+	if int64(len(data)) > s.policy.MaxSize {
+		return nil, fmt.Errorf("%w: image cannot exceed %d bytes", services.ErrPayloadTooLarge, s.policy.MaxSize)
+	}
+
+	contentType := http.DetectContentType(data)
+	if !domain.IsSupportedImageType(contentType) {
+		return nil, fmt.Errorf("%w: image must be a JPEG, PNG or WebP file", services.ErrInvalidInput)
+	}
+
+	// Only the header is decoded, so oversized images are rejected before any pixel data is read
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: image is corrupted", services.ErrInvalidInput)
+	}
+	if cfg.Width > s.policy.MaxWidth || cfg.Height > s.policy.MaxHeight {
+		return nil, fmt.Errorf("%w: image cannot exceed %dx%d pixels", services.ErrInvalidInput, s.policy.MaxWidth, s.policy.MaxHeight)
+	}
+
+	key, err := s.newKey(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("images.Upload: %w", err)
+	}
+	if err := s.store.Put(ctx, key, data, contentType); err != nil {
+		return nil, fmt.Errorf("store.Put: %w", err)
+	}
+
+	img := &domain.UploadedImage{
+		UserID:      userID,
+		Key:         key,
+		URL:         s.store.URL(key),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}
+	if err := s.imageRepo.CreateImage(ctx, img); err != nil {
+		return nil, fmt.Errorf("imageRepo.CreateImage: %w", err)
+	}
+	return img, nil
+}
+
+// newKey returns an unguessable key grouped by upload date, e.g. "2026/10/16/3f9a...c1.jpg".
+func (s *Service) newKey(contentType string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return s.now().UTC().Format("2006/01/02/") + hex.EncodeToString(b) + extensions[contentType], nil
+}
@@ -0,0 +1,176 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockBlobStore is a mock implementation of BlobStore for testing.
+type mockBlobStore struct {
+	PutFunc func(ctx context.Context, key string, data []byte, contentType string) error
+	GetFunc func(ctx context.Context, key string) ([]byte, error)
+}
+
+func (m *mockBlobStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if m.PutFunc != nil {
+		return m.PutFunc(ctx, key, data, contentType)
+	}
+	return nil
+}
+
+func (m *mockBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return m.GetFunc(ctx, key)
+}
+
+func (m *mockBlobStore) URL(key string) string {
+	return "https://cdn.example.com/" + key
+}
+
+// mockImageRepository is a mock implementation of ImageRepository for testing.
+type mockImageRepository struct {
+	CreateImageFunc        func(ctx context.Context, img *domain.UploadedImage) error
+	ClaimPendingImagesFunc func(ctx context.Context, limit int) ([]domain.UploadedImage, error)
+	SetImageVariantsFunc   func(ctx context.Context, id int64, variants []domain.ImageVariant) error
+	FailImageFunc          func(ctx context.Context, id int64, maxAttempts int) error
+}
+
+func (m *mockImageRepository) CreateImage(ctx context.Context, img *domain.UploadedImage) error {
+	if m.CreateImageFunc != nil {
+		return m.CreateImageFunc(ctx, img)
+	}
+	return nil
+}
+
+func (m *mockImageRepository) ClaimPendingImages(ctx context.Context, limit int) ([]domain.UploadedImage, error) {
+	return m.ClaimPendingImagesFunc(ctx, limit)
+}
+
+func (m *mockImageRepository) SetImageVariants(ctx context.Context, id int64, variants []domain.ImageVariant) error {
+	return m.SetImageVariantsFunc(ctx, id, variants)
+}
+
+func (m *mockImageRepository) FailImage(ctx context.Context, id int64, maxAttempts int) error {
+	return m.FailImageFunc(ctx, id, maxAttempts)
+}
+
+func encodePNG(w, h int) []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)))
+	return buf.Bytes()
+}
+
+func encodeJPEG(w, h int) []byte {
+	var buf bytes.Buffer
+	_ = jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil)
+	return buf.Bytes()
+}
+
+// webpHeader returns the header of a lossless WebP image, enough for image.DecodeConfig.
+func webpHeader(w, h int) []byte {
+	bits := make([]byte, 4)
+	binary.LittleEndian.PutUint32(bits, uint32(w-1)|uint32(h-1)<<14)
+	chunk := append([]byte{0x2f}, bits...)
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(4+8+len(chunk)))
+	buf.WriteString("WEBPVP8L")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(chunk)))
+	buf.Write(chunk)
+	return buf.Bytes()
+}
+
+func TestService_Upload(t *testing.T) {
+	policy := UploadPolicy{MaxSize: 64 << 10, MaxWidth: 100, MaxHeight: 100}
+
+	tests := []struct {
+		name         string
+		data         []byte
+		putErr       error
+		expectedType string
+		expectedExt  string
+		expectedErr  error
+	}{
+		{name: "PNG", data: encodePNG(40, 30), expectedType: domain.ImageTypePNG, expectedExt: ".png"},
+		{name: "JPEG", data: encodeJPEG(40, 30), expectedType: domain.ImageTypeJPEG, expectedExt: ".jpg"},
+		{name: "WebP", data: webpHeader(40, 30), expectedType: domain.ImageTypeWebP, expectedExt: ".webp"},
+		{name: "Empty", data: nil, expectedErr: services.ErrInvalidInput},
+		{name: "Not an image", data: []byte("<html><body>hello</body></html>"), expectedErr: services.ErrInvalidInput},
+		{name: "GIF is not supported", data: []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00"), expectedErr: services.ErrInvalidInput},
+		{name: "Corrupted PNG", data: encodePNG(40, 30)[:20], expectedErr: services.ErrInvalidInput},
+		{name: "Too wide", data: encodePNG(101, 30), expectedErr: services.ErrInvalidInput},
+		{name: "Too large", data: append(encodePNG(40, 30), make([]byte, 64<<10)...), expectedErr: services.ErrPayloadTooLarge},
+		{name: "Store fails", data: encodePNG(40, 30), putErr: errors.New("disk full"), expectedType: domain.ImageTypePNG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var storedKey string
+			store := &mockBlobStore{
+				PutFunc: func(ctx context.Context, key string, data []byte, contentType string) error {
+					storedKey = key
+					assert.Equal(t, tt.data, data)
+					assert.Equal(t, tt.expectedType, contentType)
+					return tt.putErr
+				},
+			}
+			var created *domain.UploadedImage
+			repo := &mockImageRepository{
+				CreateImageFunc: func(ctx context.Context, img *domain.UploadedImage) error {
+					created = img
+					return nil
+				},
+			}
+			service := New(store, repo, policy)
+			service.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+
+			img, err := service.Upload(context.Background(), 7, bytes.NewReader(tt.data))
+
+			if tt.putErr != nil {
+				assert.ErrorIs(t, err, tt.putErr)
+				return
+			}
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, storedKey)
+				assert.Nil(t, created)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(img.Key, "2026/10/16/"), img.Key)
+			assert.True(t, strings.HasSuffix(img.Key, tt.expectedExt), img.Key)
+			assert.Equal(t, storedKey, img.Key)
+			assert.Equal(t, "https://cdn.example.com/"+img.Key, img.URL)
+			assert.Equal(t, 40, img.Width)
+			assert.Equal(t, 30, img.Height)
+			assert.Equal(t, int64(len(tt.data)), img.Size)
+			assert.Equal(t, int64(7), img.UserID)
+			assert.Same(t, img, created)
+		})
+	}
+}
+
+func TestService_Upload_RequestTooLarge(t *testing.T) {
+	service := New(&mockBlobStore{}, &mockImageRepository{}, UploadPolicy{MaxSize: 64 << 10, MaxWidth: 100, MaxHeight: 100})
+
+	// The request limit is hit before the image size limit
+	body := http.MaxBytesReader(httptest.NewRecorder(), io.NopCloser(bytes.NewReader(encodePNG(40, 30))), 16)
+	_, err := service.Upload(context.Background(), 7, body)
+
+	assert.ErrorIs(t, err, services.ErrPayloadTooLarge)
+}
@@ -0,0 +1,170 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"golang.org/x/image/draw"
+)
+
+const (
+	// variantBatchSize is the number of images claimed by the worker at once.
+	variantBatchSize = 10
+
+	// maxVariantAttempts is the number of attempts before an image is given up on.
+	maxVariantAttempts = 3
+
+	// variantJPEGQuality is the quality of JPEG variants.
+	variantJPEGQuality = 85
+)
+
+// DefaultVariantSizes are the bounding boxes of generated variants, in pixels.
+var DefaultVariantSizes = []int{200, 800}
+
+// VariantWorker generates resized variants of uploaded images in the background.
+// Images are claimed from the repository, so several workers may run at once.
+type VariantWorker struct {
+	store     BlobStore
+	imageRepo ImageRepository
+	sizes     []int
+	interval  time.Duration
+	log       *slog.Logger
+}
+
+// NewVariantWorker creates a worker that fits variants into squares of the given
+// sizes, ascending, and polls for new images every interval.
+// DefaultVariantSizes are used if sizes is empty.
+func NewVariantWorker(store BlobStore, imageRepo ImageRepository, sizes []int, interval time.Duration, log *slog.Logger) *VariantWorker {
+	if len(sizes) == 0 {
+		sizes = DefaultVariantSizes
+	}
+	return &VariantWorker{store: store, imageRepo: imageRepo, sizes: sizes, interval: interval, log: log}
+}
+
+// Run processes pending images until ctx is canceled. A batch claimed before
+// ctx is canceled is finished first.
+func (w *VariantWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		// A full batch means more images may be pending, so poll again right away
+		if w.processBatch(ctx) == variantBatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processBatch claims and processes a batch of images, returning the number claimed.
+func (w *VariantWorker) processBatch(ctx context.Context) int {
+	imgs, err := w.imageRepo.ClaimPendingImages(ctx, variantBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.log.Error("failed to claim images", slog.String("error", err.Error()))
+		}
+		return 0
+	}
+
+	// Claimed images are finished even during shutdown, so they are not left
+	// in processing until the claim times out
+	jobCtx := context.WithoutCancel(ctx)
+	for i := range imgs {
+		w.process(jobCtx, &imgs[i])
+	}
+	return len(imgs)
+}
+
+func (w *VariantWorker) process(ctx context.Context, img *domain.UploadedImage) {
+	variants, err := w.generate(ctx, img)
+	if err != nil {
+		w.log.Error("failed to generate image variants", slog.String("key", img.Key), slog.String("error", err.Error()))
+		if err := w.imageRepo.FailImage(ctx, img.ID, maxVariantAttempts); err != nil {
+			w.log.Error("failed to release image", slog.String("key", img.Key), slog.String("error", err.Error()))
+		}
+		return
+	}
+	if err := w.imageRepo.SetImageVariants(ctx, img.ID, variants); err != nil {
+		w.log.Error("failed to save image variants", slog.String("key", img.Key), slog.String("error", err.Error()))
+	}
+}
+
+// generate writes a variant of img for every size next to the original.
+// Images already within a size are not upscaled; the original is used instead.
+func (w *VariantWorker) generate(ctx context.Context, img *domain.UploadedImage) ([]domain.ImageVariant, error) {
+	data, err := w.store.Get(ctx, img.Key)
+	if err != nil {
+		return nil, fmt.Errorf("store.Get: %w", err)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	variants := make([]domain.ImageVariant, 0, len(w.sizes))
+	for _, size := range w.sizes {
+		width, height := fitSize(img.Width, img.Height, size)
+		if width == img.Width && height == img.Height {
+			variants = append(variants, domain.ImageVariant{URL: img.URL, Width: width, Height: height})
+			continue
+		}
+
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+		encoded, contentType, err := encodeVariant(dst, img.ContentType)
+		if err != nil {
+			return nil, fmt.Errorf("encode: %w", err)
+		}
+		key := variantKey(img.Key, size, contentType)
+		if err := w.store.Put(ctx, key, encoded, contentType); err != nil {
+			return nil, fmt.Errorf("store.Put: %w", err)
+		}
+		variants = append(variants, domain.ImageVariant{URL: w.store.URL(key), Width: width, Height: height})
+	}
+	return variants, nil
+}
+
+// fitSize scales width and height down to fit into a size x size square, keeping the aspect ratio.
+func fitSize(width, height, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+	if width >= height {
+		return size, max(1, height*size/width)
+	}
+	return max(1, width*size/height), size
+}
+
+// encodeVariant encodes a variant as JPEG, unless the original may be transparent
+// and the variant actually is.
+func encodeVariant(img *image.RGBA, originalType string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if originalType != domain.ImageTypeJPEG && !img.Opaque() {
+		err := png.Encode(&buf, img)
+		return buf.Bytes(), domain.ImageTypePNG, err
+	}
+	err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: variantJPEGQuality})
+	return buf.Bytes(), domain.ImageTypeJPEG, err
+}
+
+// variantKey returns the key of a variant next to the original, e.g. "2026/10/16/abc_200.jpg".
+func variantKey(key string, size int, contentType string) string {
+	base := strings.TrimSuffix(key, path.Ext(key))
+	return base + "_" + strconv.Itoa(size) + extensions[contentType]
+}
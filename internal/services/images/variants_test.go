@@ -0,0 +1,148 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitSize(t *testing.T) {
+	tests := []struct {
+		width, height, size  int
+		expectedW, expectedH int
+	}{
+		{1600, 1200, 800, 800, 600},
+		{1200, 1600, 800, 600, 800},
+		{1000, 1000, 200, 200, 200},
+		{640, 480, 800, 640, 480},
+		{4000, 10, 200, 200, 1},
+	}
+
+	for _, tt := range tests {
+		w, h := fitSize(tt.width, tt.height, tt.size)
+		assert.Equal(t, tt.expectedW, w)
+		assert.Equal(t, tt.expectedH, h)
+	}
+}
+
+func TestVariantWorker_Generate(t *testing.T) {
+	tests := []struct {
+		name             string
+		img              domain.UploadedImage
+		data             []byte
+		expectedKeys     []string
+		expectedVariants []domain.ImageVariant
+		expectedErr      bool
+	}{
+		{
+			name:         "JPEG",
+			img:          domain.UploadedImage{Key: "2026/10/16/a.jpg", ContentType: domain.ImageTypeJPEG, Width: 1000, Height: 500},
+			data:         encodeJPEG(1000, 500),
+			expectedKeys: []string{"2026/10/16/a_200.jpg", "2026/10/16/a_800.jpg"},
+			expectedVariants: []domain.ImageVariant{
+				{URL: "https://cdn.example.com/2026/10/16/a_200.jpg", Width: 200, Height: 100},
+				{URL: "https://cdn.example.com/2026/10/16/a_800.jpg", Width: 800, Height: 400},
+			},
+		},
+		{
+			name:         "Small image is not upscaled",
+			img:          domain.UploadedImage{Key: "2026/10/16/b.png", URL: "https://cdn.example.com/2026/10/16/b.png", ContentType: domain.ImageTypePNG, Width: 300, Height: 400},
+			data:         encodePNG(300, 400),
+			expectedKeys: []string{"2026/10/16/b_200.png"},
+			expectedVariants: []domain.ImageVariant{
+				{URL: "https://cdn.example.com/2026/10/16/b_200.png", Width: 150, Height: 200},
+				{URL: "https://cdn.example.com/2026/10/16/b.png", Width: 300, Height: 400},
+			},
+		},
+		{
+			name:        "Corrupted original",
+			img:         domain.UploadedImage{Key: "2026/10/16/c.png", ContentType: domain.ImageTypePNG, Width: 300, Height: 400},
+			data:        encodePNG(300, 400)[:40],
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var storedKeys []string
+			store := &mockBlobStore{
+				GetFunc: func(ctx context.Context, key string) ([]byte, error) {
+					assert.Equal(t, tt.img.Key, key)
+					return tt.data, nil
+				},
+				PutFunc: func(ctx context.Context, key string, data []byte, contentType string) error {
+					storedKeys = append(storedKeys, key)
+					cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+					require.NoError(t, err)
+					assert.Equal(t, tt.img.ContentType, "image/"+format)
+					assert.Equal(t, tt.img.ContentType, contentType)
+					assert.LessOrEqual(t, max(cfg.Width, cfg.Height), 800)
+					return nil
+				},
+			}
+			worker := NewVariantWorker(store, &mockImageRepository{}, nil, time.Second, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			variants, err := worker.generate(context.Background(), &tt.img)
+
+			if tt.expectedErr {
+				assert.Error(t, err)
+				assert.Empty(t, storedKeys)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedKeys, storedKeys)
+			assert.Equal(t, tt.expectedVariants, variants)
+		})
+	}
+}
+
+func TestVariantWorker_ProcessBatch(t *testing.T) {
+	imgs := []domain.UploadedImage{
+		{ID: 1, Key: "a.png", ContentType: domain.ImageTypePNG, Width: 100, Height: 100},
+		{ID: 2, Key: "b.png", ContentType: domain.ImageTypePNG, Width: 100, Height: 100},
+	}
+	store := &mockBlobStore{
+		GetFunc: func(ctx context.Context, key string) ([]byte, error) {
+			if key == "b.png" {
+				return nil, errors.New("not found")
+			}
+			return encodePNG(100, 100), nil
+		},
+	}
+
+	var done, failed []int64
+	repo := &mockImageRepository{
+		ClaimPendingImagesFunc: func(ctx context.Context, limit int) ([]domain.UploadedImage, error) {
+			assert.Equal(t, variantBatchSize, limit)
+			return imgs, nil
+		},
+		SetImageVariantsFunc: func(ctx context.Context, id int64, variants []domain.ImageVariant) error {
+			done = append(done, id)
+			return nil
+		},
+		FailImageFunc: func(ctx context.Context, id int64, maxAttempts int) error {
+			assert.Equal(t, maxVariantAttempts, maxAttempts)
+			failed = append(failed, id)
+			return nil
+		},
+	}
+	worker := NewVariantWorker(store, repo, []int{50}, time.Second, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Claimed images are processed even if the worker is being stopped
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n := worker.processBatch(ctx)
+
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []int64{1}, done)
+	assert.Equal(t, []int64{2}, failed)
+}
@@ -0,0 +1,95 @@
+package rates
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+)
+
+// RateRepository defines the interface for exchange rate storage.
+type RateRepository interface {
+	ListExchangeRates(ctx context.Context) ([]domain.ExchangeRate, error)
+	SetExchangeRates(ctx context.Context, rates []domain.ExchangeRate) error
+}
+
+// UserRepository defines the interface for user lookups needed to authorize rate updates.
+type UserRepository interface {
+	FindUserByID(ctx context.Context, id int64) (*domain.User, error)
+}
+
+// Service provides exchange rate operations.
+type Service struct {
+	rateRepo RateRepository
+	userRepo UserRepository
+}
+
+// New creates a new exchange rate service.
+func New(rateRepo RateRepository, userRepo UserRepository) *Service {
+	return &Service{rateRepo: rateRepo, userRepo: userRepo}
+}
+
+// ListRates returns the current exchange rates, quoted in domain.BaseCurrency.
+func (s *Service) ListRates(ctx context.Context) ([]domain.ExchangeRate, error) {
+	rates, err := s.rateRepo.ListExchangeRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("rateRepo.ListExchangeRates: %w", err)
+	}
+	return rates, nil
+}
+
+// SetRates replaces the exchange rates of the given currencies and returns all rates.
+// Only administrators may change rates; the rate of the base currency is fixed at 1.
+func (s *Service) SetRates(ctx context.Context, userID int64, rates map[domain.Currency]float64) ([]domain.ExchangeRate, error) {
+	if err := s.checkAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("%w: no rates given", services.ErrInvalidInput)
+	}
+
+	updates := make([]domain.ExchangeRate, 0, len(rates))
+	for currency, rate := range rates {
+		if !currency.Valid() {
+			return nil, fmt.Errorf("%w: unsupported currency %q", services.ErrInvalidInput, currency)
+		}
+		if currency == domain.BaseCurrency {
+			return nil, fmt.Errorf("%w: the rate of %s is always 1", services.ErrInvalidInput, currency)
+		}
+		if !(rate > 0) || math.IsInf(rate, 0) {
+			return nil, fmt.Errorf("%w: the rate of %s must be a positive number", services.ErrInvalidInput, currency)
+		}
+		updates = append(updates, domain.ExchangeRate{Currency: currency, Rate: rate})
+	}
+	slices.SortFunc(updates, func(a, b domain.ExchangeRate) int { return cmp.Compare(a.Currency, b.Currency) })
+
+	if err := s.rateRepo.SetExchangeRates(ctx, updates); err != nil {
+		return nil, fmt.Errorf("rateRepo.SetExchangeRates: %w", err)
+	}
+	return s.ListRates(ctx)
+}
+
+// checkAdmin returns nil if userID is an administrator.
+func (s *Service) checkAdmin(ctx context.Context, userID int64) error {
+	if userID == 0 {
+		return services.ErrUnauthorized
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return services.ErrUnauthorized
+		}
+		return fmt.Errorf("userRepo.FindUserByID: %w", err)
+	}
+	if !user.IsAdmin {
+		return services.ErrForbidden
+	}
+	return nil
+}
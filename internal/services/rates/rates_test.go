@@ -0,0 +1,110 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/services"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockRateRepository is a mock implementation of RateRepository for testing.
+type mockRateRepository struct {
+	ListExchangeRatesFunc func(ctx context.Context) ([]domain.ExchangeRate, error)
+	SetExchangeRatesFunc  func(ctx context.Context, rates []domain.ExchangeRate) error
+}
+
+func (m *mockRateRepository) ListExchangeRates(ctx context.Context) ([]domain.ExchangeRate, error) {
+	if m.ListExchangeRatesFunc != nil {
+		return m.ListExchangeRatesFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockRateRepository) SetExchangeRates(ctx context.Context, rates []domain.ExchangeRate) error {
+	if m.SetExchangeRatesFunc != nil {
+		return m.SetExchangeRatesFunc(ctx, rates)
+	}
+	return nil
+}
+
+// mockUserRepository is a mock implementation of UserRepository for testing.
+type mockUserRepository struct {
+	users map[int64]*domain.User
+}
+
+func (m *mockUserRepository) FindUserByID(ctx context.Context, id int64) (*domain.User, error) {
+	if u, ok := m.users[id]; ok {
+		return u, nil
+	}
+	return nil, storage.ErrUserNotFound
+}
+
+func TestService_SetRates(t *testing.T) {
+	users := &mockUserRepository{users: map[int64]*domain.User{
+		1: {ID: 1, Login: "admin", IsAdmin: true},
+		2: {ID: 2, Login: "seller"},
+	}}
+
+	tests := []struct {
+		name        string
+		userID      int64
+		rates       map[domain.Currency]float64
+		expectedErr error
+	}{
+		{name: "Administrator", userID: 1, rates: map[domain.Currency]float64{domain.CurrencyKZT: 0.19, domain.CurrencyBYN: 28.5}},
+		{name: "Regular user", userID: 2, rates: map[domain.Currency]float64{domain.CurrencyKZT: 0.19}, expectedErr: services.ErrForbidden},
+		{name: "Unknown user", userID: 3, rates: map[domain.Currency]float64{domain.CurrencyKZT: 0.19}, expectedErr: services.ErrUnauthorized},
+		{name: "Anonymous", userID: 0, rates: map[domain.Currency]float64{domain.CurrencyKZT: 0.19}, expectedErr: services.ErrUnauthorized},
+		{name: "No rates", userID: 1, rates: map[domain.Currency]float64{}, expectedErr: services.ErrInvalidInput},
+		{name: "Unsupported currency", userID: 1, rates: map[domain.Currency]float64{"USD": 95}, expectedErr: services.ErrInvalidInput},
+		{name: "Base currency", userID: 1, rates: map[domain.Currency]float64{domain.CurrencyRUB: 2}, expectedErr: services.ErrInvalidInput},
+		{name: "Zero rate", userID: 1, rates: map[domain.Currency]float64{domain.CurrencyKZT: 0}, expectedErr: services.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []domain.ExchangeRate
+			repo := &mockRateRepository{
+				SetExchangeRatesFunc: func(ctx context.Context, rates []domain.ExchangeRate) error {
+					saved = rates
+					return nil
+				},
+				ListExchangeRatesFunc: func(ctx context.Context) ([]domain.ExchangeRate, error) {
+					return append([]domain.ExchangeRate{{Currency: domain.CurrencyRUB, Rate: 1}}, saved...), nil
+				},
+			}
+			service := New(repo, users)
+
+			rates, err := service.SetRates(context.Background(), tt.userID, tt.rates)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, saved)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, []domain.ExchangeRate{
+				{Currency: domain.CurrencyBYN, Rate: 28.5},
+				{Currency: domain.CurrencyKZT, Rate: 0.19},
+			}, saved)
+			assert.Len(t, rates, 3)
+		})
+	}
+
+	t.Run("Repository error", func(t *testing.T) {
+		repo := &mockRateRepository{
+			SetExchangeRatesFunc: func(ctx context.Context, rates []domain.ExchangeRate) error {
+				return errors.New("db error")
+			},
+		}
+		service := New(repo, users)
+
+		_, err := service.SetRates(context.Background(), 1, map[domain.Currency]float64{domain.CurrencyKZT: 0.19})
+
+		assert.ErrorContains(t, err, "db error")
+	})
+}
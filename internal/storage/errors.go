@@ -5,15 +5,27 @@ import "errors"
 // Repository layer errors - specific to storage operations
 var (
 	// User-related errors
-	ErrUserExists       = errors.New("user already exists")
-	ErrUserNotFound     = errors.New("user not found")
+	ErrUserExists         = errors.New("user already exists")
+	ErrUserNotFound       = errors.New("user not found")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 
+	// Token-related errors
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenUsed     = errors.New("refresh token already used or revoked")
+	ErrSessionNotFound      = errors.New("session not found")
+
 	// Ad-related errors
-	ErrAdExists         = errors.New("ad already exists")
-	ErrAdNotFound       = errors.New("ad not found")
+	ErrAdExists   = errors.New("ad already exists")
+	ErrAdNotFound = errors.New("ad not found")
+	ErrAdConflict = errors.New("ad was modified concurrently")
+
+	// ErrLocked is returned when a job is skipped because another replica runs it
+	ErrLocked = errors.New("locked by another process")
+
+	// Category-related errors
+	ErrCategoryNotFound = errors.New("category not found")
 
 	// Relationship errors
-	ErrForeignKeyViolation = errors.New("foreign key constraint violation")
+	ErrForeignKeyViolation  = errors.New("foreign key constraint violation")
 	ErrInvalidUserReference = errors.New("invalid user reference")
 )
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// AttemptStore counts failed attempts in memory. It implements storage.AttemptStore.
+type AttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]domain.Attempts
+	prunedAt time.Time
+}
+
+// NewAttemptStore creates an empty in-memory attempt store.
+func NewAttemptStore() *AttemptStore {
+	return &AttemptStore{attempts: make(map[string]domain.Attempts)}
+}
+
+// RecordFailure counts a failed attempt made with key at the given time and returns
+// the failures before it. Failures before since are forgotten.
+func (s *AttemptStore) RecordFailure(_ context.Context, key string, at, since time.Time) (domain.Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Stale keys are dropped about once per failure window
+	if since.After(s.prunedAt) {
+		for k, a := range s.attempts {
+			if a.LastFailedAt.Before(since) {
+				delete(s.attempts, k)
+			}
+		}
+		s.prunedAt = at
+	}
+
+	prev := s.attempts[key]
+	if prev.LastFailedAt.Before(since) {
+		prev = domain.Attempts{}
+	}
+	a := domain.Attempts{Failures: prev.Failures + 1, LastFailedAt: prev.LastFailedAt}
+	if at.After(a.LastFailedAt) {
+		a.LastFailedAt = at
+	}
+	s.attempts[key] = a
+
+	return prev, nil
+}
+
+// RevertFailure undoes a failed attempt recorded with key, which RecordFailure
+// returned prev for. Unless other attempts were recorded since, the time of the
+// last failure is restored as well.
+func (s *AttemptStore) RevertFailure(_ context.Context, key string, prev domain.Attempts) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.attempts[key]
+	switch {
+	case !ok:
+	case a.Failures <= 1:
+		delete(s.attempts, key)
+	case a.Failures == prev.Failures+1:
+		s.attempts[key] = prev
+	default:
+		a.Failures--
+		s.attempts[key] = a
+	}
+
+	return nil
+}
+
+// ResetFailures forgets the failed attempts made with key.
+func (s *AttemptStore) ResetFailures(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttemptStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	window := time.Hour
+	s := NewAttemptStore()
+
+	prev, err := s.RecordFailure(ctx, "a", now, now.Add(-window))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Attempts{}, prev)
+
+	prev, err = s.RecordFailure(ctx, "a", now.Add(time.Minute), now.Add(time.Minute-window))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Attempts{Failures: 1, LastFailedAt: now}, prev)
+
+	// Reverting the latest attempt restores the failures before it
+	require.NoError(t, s.RevertFailure(ctx, "a", prev))
+	assert.Equal(t, domain.Attempts{Failures: 1, LastFailedAt: now}, s.attempts["a"])
+
+	// Failures older than the window are forgotten and the count starts over
+	later := now.Add(2 * window)
+	prev, err = s.RecordFailure(ctx, "b", later, later.Add(-window))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Attempts{}, prev)
+	assert.NotContains(t, s.attempts, "a")
+
+	prev, err = s.RecordFailure(ctx, "a", later, later.Add(-window))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Attempts{}, prev)
+
+	require.NoError(t, s.RevertFailure(ctx, "a", prev))
+	assert.NotContains(t, s.attempts, "a")
+
+	require.NoError(t, s.ResetFailures(ctx, "b"))
+	assert.NotContains(t, s.attempts, "b")
+}
+
+func TestAttemptStore_RevertFailure_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewAttemptStore()
+
+	first, err := s.RecordFailure(ctx, "a", now, now.Add(-time.Hour))
+	require.NoError(t, err)
+	_, err = s.RecordFailure(ctx, "a", now.Add(time.Second), now.Add(-time.Hour))
+	require.NoError(t, err)
+
+	// Another attempt was recorded since, so only the count goes down
+	require.NoError(t, s.RevertFailure(ctx, "a", first))
+	assert.Equal(t, domain.Attempts{Failures: 1, LastFailedAt: now.Add(time.Second)}, s.attempts["a"])
+}
@@ -0,0 +1,48 @@
+// Package memory provides in-process implementations of storage interfaces for
+// single-instance deployments and tests. Their data is lost on restart.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RevocationStore keeps revoked access token IDs in memory. It implements
+// storage.RevocationStore.
+type RevocationStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time // jti -> token expiry
+	now     func() time.Time
+}
+
+// NewRevocationStore creates an empty in-memory revocation store.
+func NewRevocationStore() *RevocationStore {
+	return &RevocationStore{revoked: make(map[string]time.Time), now: time.Now}
+}
+
+// RevokeToken records an access token as revoked until it expires. Records of
+// tokens that have expired by now are removed on the way.
+func (s *RevocationStore) RevokeToken(_ context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, exp := range s.revoked {
+		if !exp.After(now) {
+			delete(s.revoked, id)
+		}
+	}
+	s.revoked[jti] = expiresAt
+
+	return nil
+}
+
+// IsTokenRevoked reports whether an access token has been revoked.
+func (s *RevocationStore) IsTokenRevoked(_ context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exp, ok := s.revoked[jti]
+	return ok && exp.After(s.now()), nil
+}
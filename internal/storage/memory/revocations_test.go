@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevocationStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewRevocationStore()
+	s.now = func() time.Time { return now }
+
+	require.NoError(t, s.RevokeToken(ctx, "a", now.Add(time.Minute)))
+
+	revoked, err := s.IsTokenRevoked(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = s.IsTokenRevoked(ctx, "b")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Once the token expires, its record is no longer needed and is pruned
+	now = now.Add(2 * time.Minute)
+	revoked, err = s.IsTokenRevoked(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, s.RevokeToken(ctx, "b", now.Add(time.Minute)))
+	assert.NotContains(t, s.revoked, "a")
+	assert.Contains(t, s.revoked, "b")
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// RecordFailure counts a failed attempt made with key at the given time and returns
+// the failures before it. Failures before since are forgotten; stale rows of other
+// keys are removed on the way. The upsert locks the row, so concurrent attempts
+// with one key are counted one after another.
+func (s *Storage) RecordFailure(ctx context.Context, key string, at, since time.Time) (domain.Attempts, error) {
+	const q = `
+		WITH pruned AS (DELETE FROM login_failures WHERE last_failed_at < $3 AND key <> $1)
+		INSERT INTO login_failures AS f (key, failures, last_failed_at) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN f.last_failed_at >= $3 THEN f.failures + 1 ELSE 1 END,
+			previous_failed_at = CASE WHEN f.last_failed_at >= $3 THEN f.last_failed_at END,
+			last_failed_at = GREATEST(f.last_failed_at, EXCLUDED.last_failed_at)
+		RETURNING failures - 1, previous_failed_at`
+
+	var (
+		prev         domain.Attempts
+		lastFailedAt *time.Time
+	)
+	if err := s.pool.QueryRow(ctx, q, key, at, since).Scan(&prev.Failures, &lastFailedAt); err != nil {
+		return domain.Attempts{}, fmt.Errorf("storage.RecordFailure: %w", err)
+	}
+	if lastFailedAt != nil {
+		prev.LastFailedAt = *lastFailedAt
+	}
+
+	return prev, nil
+}
+
+// RevertFailure undoes a failed attempt recorded with key, which RecordFailure
+// returned prev for. Unless other attempts were recorded since, the time of the
+// last failure is restored as well. A key left without failures is removed.
+func (s *Storage) RevertFailure(ctx context.Context, key string, prev domain.Attempts) error {
+	const q = `
+		WITH reverted AS (
+			UPDATE login_failures SET
+				failures = failures - 1,
+				last_failed_at = CASE WHEN failures = $2 + 1 THEN $3 ELSE last_failed_at END
+			WHERE key = $1 AND failures > 1
+		)
+		DELETE FROM login_failures WHERE key = $1 AND failures <= 1`
+
+	if _, err := s.pool.Exec(ctx, q, key, prev.Failures, prev.LastFailedAt); err != nil {
+		return fmt.Errorf("storage.RevertFailure: %w", err)
+	}
+
+	return nil
+}
+
+// ResetFailures forgets the failed attempts made with key.
+func (s *Storage) ResetFailures(ctx context.Context, key string) error {
+	const q = `DELETE FROM login_failures WHERE key = $1`
+
+	if _, err := s.pool.Exec(ctx, q, key); err != nil {
+		return fmt.Errorf("storage.ResetFailures: %w", err)
+	}
+
+	return nil
+}
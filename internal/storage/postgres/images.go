@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// imageClaimTimeout is how long a claimed image may be processed before
+// another worker takes it over, e.g. after a crash.
+const imageClaimTimeout = "5 minutes"
+
+// CreateImage records an uploaded image; its variants are pending.
+func (s *Storage) CreateImage(ctx context.Context, img *domain.UploadedImage) error {
+	const q = `INSERT INTO images (user_id, key, url, content_type, size, width, height) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	err := s.pool.QueryRow(ctx, q, img.UserID, img.Key, img.URL, img.ContentType, img.Size, img.Width, img.Height).Scan(&img.ID)
+	if err != nil {
+		return fmt.Errorf("storage.CreateImage: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimPendingImages marks up to limit images with pending variants as being processed
+// and returns them. Rows locked by a concurrent claim are skipped.
+func (s *Storage) ClaimPendingImages(ctx context.Context, limit int) ([]domain.UploadedImage, error) {
+	const q = `
+		UPDATE images SET variants_status = 'processing', claimed_at = NOW(), attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM images
+			WHERE variants_status = 'pending'
+				OR (variants_status = 'processing' AND claimed_at < NOW() - INTERVAL '` + imageClaimTimeout + `')
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, key, url, content_type, size, width, height`
+
+	rows, err := s.pool.Query(ctx, q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("storage.ClaimPendingImages: %w", err)
+	}
+
+	images, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.UploadedImage])
+	if err != nil {
+		return nil, fmt.Errorf("storage.ClaimPendingImages: %w", err)
+	}
+
+	return images, nil
+}
+
+// SetImageVariants stores the generated variants of an image.
+func (s *Storage) SetImageVariants(ctx context.Context, id int64, variants []domain.ImageVariant) error {
+	const q = `UPDATE images SET variants = $2, variants_status = 'done', claimed_at = NULL WHERE id = $1`
+
+	if _, err := s.pool.Exec(ctx, q, id, variants); err != nil {
+		return fmt.Errorf("storage.SetImageVariants: %w", err)
+	}
+
+	return nil
+}
+
+// FailImage returns an image to the queue after a failed attempt,
+// or gives up on it after maxAttempts.
+func (s *Storage) FailImage(ctx context.Context, id int64, maxAttempts int) error {
+	const q = `
+		UPDATE images SET claimed_at = NULL,
+			variants_status = CASE WHEN attempts >= $2 THEN 'failed' ELSE 'pending' END
+		WHERE id = $1`
+
+	if _, err := s.pool.Exec(ctx, q, id, maxAttempts); err != nil {
+		return fmt.Errorf("storage.FailImage: %w", err)
+	}
+
+	return nil
+}
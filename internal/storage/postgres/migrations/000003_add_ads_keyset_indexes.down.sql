@@ -0,0 +1,3 @@
+-- Drop keyset pagination indexes
+DROP INDEX IF EXISTS idx_ads_price_id;
+DROP INDEX IF EXISTS idx_ads_created_at_id;
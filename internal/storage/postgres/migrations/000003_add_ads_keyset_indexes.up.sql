@@ -0,0 +1,3 @@
+-- Indexes backing keyset pagination of the ad feed
+CREATE INDEX IF NOT EXISTS idx_ads_created_at_id ON ads (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_ads_price_id ON ads (price, id);
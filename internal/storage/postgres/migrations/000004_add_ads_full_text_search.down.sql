@@ -0,0 +1,3 @@
+-- Remove full-text search column and index
+DROP INDEX IF EXISTS idx_ads_search_vector;
+ALTER TABLE ads DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,8 @@
+-- Full-text search over ad title (weight A) and text (weight B)
+ALTER TABLE ads ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(text, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_ads_search_vector ON ads USING GIN (search_vector);
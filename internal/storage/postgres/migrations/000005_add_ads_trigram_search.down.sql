@@ -0,0 +1,2 @@
+-- Remove trigram index; the extension is left installed as other objects may use it
+DROP INDEX IF EXISTS idx_ads_title_trgm;
@@ -0,0 +1,4 @@
+-- Trigram indexes for typo-tolerant search and title autocomplete
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_ads_title_trgm ON ads USING GIN (title gin_trgm_ops);
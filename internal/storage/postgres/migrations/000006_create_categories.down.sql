@@ -0,0 +1,4 @@
+-- Remove categories and the category reference from ads
+DROP INDEX IF EXISTS idx_ads_category_id;
+ALTER TABLE ads DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS categories;
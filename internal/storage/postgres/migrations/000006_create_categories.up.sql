@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id BIGSERIAL PRIMARY KEY,
+    parent_id BIGINT REFERENCES categories(id) ON DELETE RESTRICT,
+    name VARCHAR(64) NOT NULL,
+    slug VARCHAR(64) NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+
+-- Seed the category tree: top-level categories first, then their children
+INSERT INTO categories (name, slug) VALUES
+    ('Transport', 'transport'),
+    ('Real estate', 'real-estate'),
+    ('Electronics', 'electronics'),
+    ('Home and garden', 'home-and-garden'),
+    ('Clothing', 'clothing'),
+    ('Other', 'other');
+
+INSERT INTO categories (parent_id, name, slug)
+SELECT p.id, c.name, c.slug
+FROM (VALUES
+    ('transport', 'Cars', 'cars'),
+    ('transport', 'Motorcycles', 'motorcycles'),
+    ('transport', 'Bicycles', 'bicycles'),
+    ('real-estate', 'Flats', 'flats'),
+    ('real-estate', 'Houses', 'houses'),
+    ('real-estate', 'Rooms', 'rooms'),
+    ('electronics', 'Phones', 'phones'),
+    ('electronics', 'Computers', 'computers'),
+    ('electronics', 'Audio and video', 'audio-and-video'),
+    ('home-and-garden', 'Furniture', 'furniture'),
+    ('home-and-garden', 'Appliances', 'appliances'),
+    ('clothing', 'Men', 'clothing-men'),
+    ('clothing', 'Women', 'clothing-women'),
+    ('clothing', 'Kids', 'clothing-kids')
+) AS c(parent_slug, name, slug)
+JOIN categories p ON p.slug = c.parent_slug;
+
+-- Every ad belongs to a category; existing ads go to "Other"
+ALTER TABLE ads ADD COLUMN category_id BIGINT REFERENCES categories(id) ON DELETE RESTRICT;
+
+UPDATE ads SET category_id = (SELECT id FROM categories WHERE slug = 'other');
+
+ALTER TABLE ads ALTER COLUMN category_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ads_category_id ON ads (category_id);
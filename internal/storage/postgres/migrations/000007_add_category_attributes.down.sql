@@ -0,0 +1,4 @@
+-- Remove structured ad attributes and category schemas
+DROP INDEX IF EXISTS idx_ads_attributes;
+ALTER TABLE ads DROP COLUMN IF EXISTS attributes;
+ALTER TABLE categories DROP COLUMN IF EXISTS attribute_schema;
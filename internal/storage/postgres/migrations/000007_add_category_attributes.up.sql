@@ -0,0 +1,59 @@
+-- Per-category attribute schemas (a subset of JSON Schema) and structured ad attributes
+ALTER TABLE categories ADD COLUMN attribute_schema JSONB;
+
+ALTER TABLE ads ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_ads_attributes ON ads USING GIN (attributes jsonb_path_ops);
+
+UPDATE categories SET attribute_schema = '{
+    "type": "object",
+    "properties": {
+        "year": {"type": "integer", "minimum": 1900, "maximum": 2100},
+        "mileage": {"type": "integer", "minimum": 0},
+        "brand": {"type": "string", "maxLength": 50},
+        "transmission": {"type": "string", "enum": ["manual", "automatic"]}
+    },
+    "required": ["year"],
+    "additionalProperties": false
+}' WHERE slug = 'cars';
+
+UPDATE categories SET attribute_schema = '{
+    "type": "object",
+    "properties": {
+        "year": {"type": "integer", "minimum": 1900, "maximum": 2100},
+        "mileage": {"type": "integer", "minimum": 0},
+        "engine_cc": {"type": "integer", "minimum": 0}
+    },
+    "additionalProperties": false
+}' WHERE slug = 'motorcycles';
+
+UPDATE categories SET attribute_schema = '{
+    "type": "object",
+    "properties": {
+        "rooms": {"type": "integer", "minimum": 0, "maximum": 20},
+        "area": {"type": "number", "minimum": 1},
+        "floor": {"type": "integer"}
+    },
+    "required": ["rooms", "area"],
+    "additionalProperties": false
+}' WHERE slug = 'flats';
+
+UPDATE categories SET attribute_schema = '{
+    "type": "object",
+    "properties": {
+        "rooms": {"type": "integer", "minimum": 1, "maximum": 50},
+        "area": {"type": "number", "minimum": 1},
+        "land_area": {"type": "number", "minimum": 0}
+    },
+    "required": ["area"],
+    "additionalProperties": false
+}' WHERE slug = 'houses';
+
+UPDATE categories SET attribute_schema = '{
+    "type": "object",
+    "properties": {
+        "area": {"type": "number", "minimum": 1},
+        "floor": {"type": "integer"}
+    },
+    "additionalProperties": false
+}' WHERE slug = 'rooms';
@@ -0,0 +1,6 @@
+-- Remove ad location; the extensions are left installed as other objects may use them
+DROP INDEX IF EXISTS idx_ads_location;
+ALTER TABLE ads DROP CONSTRAINT IF EXISTS ads_location_check;
+ALTER TABLE ads DROP COLUMN IF EXISTS city;
+ALTER TABLE ads DROP COLUMN IF EXISTS longitude;
+ALTER TABLE ads DROP COLUMN IF EXISTS latitude;
@@ -0,0 +1,15 @@
+-- Ad location for local pickup and radius search (cube + earthdistance, no PostGIS required)
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+ALTER TABLE ads
+    ADD COLUMN latitude DOUBLE PRECISION,
+    ADD COLUMN longitude DOUBLE PRECISION,
+    ADD COLUMN city VARCHAR(100) NOT NULL DEFAULT '',
+    ADD CONSTRAINT ads_location_check CHECK (
+        (latitude IS NULL AND longitude IS NULL)
+        OR (latitude BETWEEN -90 AND 90 AND longitude BETWEEN -180 AND 180)
+    );
+
+-- Serves earth_box bounding-cube lookups; ads without a location are indexed as NULL
+CREATE INDEX IF NOT EXISTS idx_ads_location ON ads USING GIST (ll_to_earth(latitude, longitude));
@@ -0,0 +1,20 @@
+-- Uploaded images and their resized variants, generated by a background worker
+CREATE TABLE IF NOT EXISTS images (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key VARCHAR(255) NOT NULL UNIQUE,
+    url VARCHAR(255) NOT NULL UNIQUE,
+    content_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    variants JSONB NOT NULL DEFAULT '[]',
+    variants_status VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (variants_status IN ('pending', 'processing', 'done', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    claimed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Work queue of the variant worker
+CREATE INDEX IF NOT EXISTS idx_images_variants_queue ON images (id) WHERE variants_status IN ('pending', 'processing');
@@ -0,0 +1,15 @@
+-- Ordered images of an ad; the image at position 0 is the cover, mirrored in ads.image_url
+CREATE TABLE IF NOT EXISTS ad_images (
+    id BIGSERIAL PRIMARY KEY,
+    ad_id BIGINT NOT NULL REFERENCES ads(id) ON DELETE CASCADE,
+    url VARCHAR(255) NOT NULL,
+    position INT NOT NULL CHECK (position >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT ad_images_url_key UNIQUE (ad_id, url),
+    -- Deferred so that images can swap positions within a transaction
+    CONSTRAINT ad_images_position_key UNIQUE (ad_id, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+-- Existing single images become covers
+INSERT INTO ad_images (ad_id, url, position)
+SELECT id, image_url, 0 FROM ads WHERE image_url <> '';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_ads_user_id;
+DROP INDEX IF EXISTS idx_ads_status_created_at;
+
+ALTER TABLE ads
+    DROP COLUMN IF EXISTS status_changed_at,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,9 @@
+-- Ad lifecycle; existing ads are published
+ALTER TABLE ads
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'published'
+        CONSTRAINT ads_status_check CHECK (status IN ('draft', 'published', 'reserved', 'sold', 'archived')),
+    ADD COLUMN status_changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Every feed query filters by status
+CREATE INDEX IF NOT EXISTS idx_ads_status_created_at ON ads (status, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_ads_user_id ON ads (user_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_ads_expires_at;
+
+ALTER TABLE ads DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,7 @@
+-- Ads expire and are archived by a scheduler; existing ads get a full term
+ALTER TABLE ads ADD COLUMN expires_at TIMESTAMPTZ;
+UPDATE ads SET expires_at = NOW() + INTERVAL '30 days';
+ALTER TABLE ads ALTER COLUMN expires_at SET NOT NULL;
+
+-- Serves the expiry scheduler
+CREATE INDEX IF NOT EXISTS idx_ads_expires_at ON ads (expires_at) WHERE status IN ('published', 'reserved');
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_ads_status_bumped_at;
+CREATE INDEX IF NOT EXISTS idx_ads_status_created_at ON ads (status, created_at, id);
+
+ALTER TABLE ads DROP COLUMN IF EXISTS bumped_at;
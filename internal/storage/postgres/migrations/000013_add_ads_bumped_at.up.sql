@@ -0,0 +1,9 @@
+-- The feed is ordered by the time an ad was posted or last bumped
+ALTER TABLE ads ADD COLUMN bumped_at TIMESTAMPTZ;
+UPDATE ads SET bumped_at = created_at;
+ALTER TABLE ads
+    ALTER COLUMN bumped_at SET NOT NULL,
+    ALTER COLUMN bumped_at SET DEFAULT NOW();
+
+DROP INDEX IF EXISTS idx_ads_status_created_at;
+CREATE INDEX IF NOT EXISTS idx_ads_status_bumped_at ON ads (status, bumped_at, id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS ad_versions;
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_admin;
@@ -0,0 +1,16 @@
+-- Administrators are appointed directly in the database
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Prior states of edited ads; each row is the ad as it was before an edit
+CREATE TABLE IF NOT EXISTS ad_versions (
+    id BIGSERIAL PRIMARY KEY,
+    ad_id BIGINT NOT NULL REFERENCES ads(id) ON DELETE CASCADE,
+    title VARCHAR(120) NOT NULL,
+    text TEXT NOT NULL,
+    price BIGINT NOT NULL,
+    image_url VARCHAR(255) NOT NULL,
+    editor_id BIGINT REFERENCES users(id) ON DELETE SET NULL, -- who made the edit
+    edited_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ad_versions_ad_id ON ad_versions (ad_id, id);
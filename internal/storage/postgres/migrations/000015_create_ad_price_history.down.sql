@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_ads_price_dropped_at;
+
+ALTER TABLE ads
+    DROP COLUMN IF EXISTS price_dropped_at,
+    DROP COLUMN IF EXISTS previous_price;
+
+DROP TABLE IF EXISTS ad_price_history;
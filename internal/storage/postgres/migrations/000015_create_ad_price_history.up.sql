@@ -0,0 +1,17 @@
+-- Every price change of an ad
+CREATE TABLE IF NOT EXISTS ad_price_history (
+    id BIGSERIAL PRIMARY KEY,
+    ad_id BIGINT NOT NULL REFERENCES ads(id) ON DELETE CASCADE,
+    old_price BIGINT NOT NULL,
+    new_price BIGINT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ad_price_history_ad_id ON ad_price_history (ad_id, id);
+
+-- The latest change is kept on the ad for the feed; price_dropped_at is set while it is a drop
+ALTER TABLE ads
+    ADD COLUMN previous_price BIGINT,
+    ADD COLUMN price_dropped_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_ads_price_dropped_at ON ads (price_dropped_at) WHERE price_dropped_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE ads DROP COLUMN IF EXISTS currency;
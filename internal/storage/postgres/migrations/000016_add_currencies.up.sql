@@ -0,0 +1,12 @@
+-- Prices are in minor units of the ad currency; existing ads are in roubles
+ALTER TABLE ads ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB'
+    CONSTRAINT ads_currency_check CHECK (currency IN ('RUB', 'KZT', 'BYN'));
+
+-- Price of one unit of a currency in roubles, maintained by administrators
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency CHAR(3) PRIMARY KEY,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO exchange_rates (currency, rate) VALUES ('RUB', 1) ON CONFLICT DO NOTHING;
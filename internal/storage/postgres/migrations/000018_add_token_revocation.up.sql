@@ -0,0 +1,10 @@
+-- Access tokens carry the version of their user; bumping it logs out every session
+ALTER TABLE users ADD COLUMN token_version INT NOT NULL DEFAULT 0;
+
+-- IDs (jti) of access tokens revoked before they expire; rows are useless after expires_at
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti TEXT PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
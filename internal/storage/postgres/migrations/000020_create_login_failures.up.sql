@@ -0,0 +1,11 @@
+-- Consecutive failed logins per key, e.g. a login or an IP address.
+-- Rows whose last failure is older than the failure window are stale.
+-- previous_failed_at is the last failure before the latest one.
+CREATE TABLE IF NOT EXISTS login_failures (
+    key TEXT PRIMARY KEY,
+    failures INT NOT NULL,
+    last_failed_at TIMESTAMPTZ NOT NULL,
+    previous_failed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_failures_last_failed_at ON login_failures (last_failed_at);
@@ -0,0 +1,3 @@
+ALTER TABLE ad_price_history
+    DROP COLUMN IF EXISTS new_currency,
+    DROP COLUMN IF EXISTS old_currency;
@@ -0,0 +1,12 @@
+-- Prices in the history are in minor units of the currency recorded with them.
+-- Earlier rows are taken to be in the current currency of the ad.
+ALTER TABLE ad_price_history
+    ADD COLUMN old_currency CHAR(3),
+    ADD COLUMN new_currency CHAR(3);
+
+UPDATE ad_price_history h SET old_currency = a.currency, new_currency = a.currency
+FROM ads a WHERE a.id = h.ad_id;
+
+ALTER TABLE ad_price_history
+    ALTER COLUMN old_currency SET NOT NULL,
+    ALTER COLUMN new_currency SET NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE ad_versions DROP COLUMN IF EXISTS currency;
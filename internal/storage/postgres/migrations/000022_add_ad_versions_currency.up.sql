@@ -0,0 +1,8 @@
+-- Versions record the currency their price is in, so an edit of the currency
+-- alone is a new version. Earlier rows are taken to be in the current currency of the ad.
+ALTER TABLE ad_versions ADD COLUMN currency CHAR(3);
+
+UPDATE ad_versions v SET currency = a.currency
+FROM ads a WHERE a.id = v.ad_id;
+
+ALTER TABLE ad_versions ALTER COLUMN currency SET NOT NULL;
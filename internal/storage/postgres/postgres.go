@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/felix-kado/vk-test-task/internal/domain"
 	"github.com/felix-kado/vk-test-task/internal/storage"
@@ -14,6 +15,20 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// adColumns is the column list selected for domain.Ad.
+// Variants are only known for images uploaded through the API.
+const adColumns = "id, user_id, author_login, title, text, image_url, price, currency, category_id, attributes, latitude, longitude, city, status, status_changed_at, expires_at, bumped_at, previous_price, price_dropped_at, created_at, " +
+	"COALESCE((SELECT variants FROM images WHERE images.url = ads.image_url), '[]') AS image_variants, " +
+	`COALESCE((SELECT jsonb_agg(jsonb_build_object('id', ai.id, 'url', ai.url, 'position', ai.position, 'variants', COALESCE(i.variants, '[]')) ORDER BY ai.position)
+		FROM ad_images ai LEFT JOIN images i ON i.url = ai.url WHERE ai.ad_id = ads.id), '[]') AS images`
+
+// adsExpiryLockKey identifies the advisory lock held while archiving expired ads;
+// it must differ from the keys of other advisory locks.
+const adsExpiryLockKey int64 = 1001
+
+// adsCategoryFKey is the name of the foreign key from ads to categories.
+const adsCategoryFKey = "ads_category_id_fkey"
+
 // Storage implements the storage interfaces for PostgreSQL.
 type Storage struct {
 	pool *pgxpool.Pool
@@ -57,7 +72,7 @@ func (s *Storage) CreateUser(ctx context.Context, u *domain.User) error {
 
 // FindByLogin finds a user by their login.
 func (s *Storage) FindByLogin(ctx context.Context, login string) (*domain.User, error) {
-	const q = `SELECT id, login, password_hash, created_at FROM users WHERE login = $1`
+	const q = `SELECT id, login, password_hash, is_admin, token_version, created_at FROM users WHERE login = $1`
 
 	rows, err := s.pool.Query(ctx, q, login)
 	if err != nil {
@@ -77,7 +92,7 @@ func (s *Storage) FindByLogin(ctx context.Context, login string) (*domain.User,
 
 // FindUserByID finds a user by their ID.
 func (s *Storage) FindUserByID(ctx context.Context, id int64) (*domain.User, error) {
-	q := `SELECT id, login, password_hash, created_at FROM users WHERE id = $1`
+	q := `SELECT id, login, password_hash, is_admin, token_version, created_at FROM users WHERE id = $1`
 
 	rows, err := s.pool.Query(ctx, q, id)
 	if err != nil {
@@ -95,16 +110,40 @@ func (s *Storage) FindUserByID(ctx context.Context, id int64) (*domain.User, err
 	return &u, nil
 }
 
-// CreateAd creates a new ad in the database.
+// IncrementTokenVersion bumps the token version of a user, invalidating every
+// access token issued to them.
+func (s *Storage) IncrementTokenVersion(ctx context.Context, id int64) error {
+	const q = `UPDATE users SET token_version = token_version + 1 WHERE id = $1`
+
+	tag, err := s.pool.Exec(ctx, q, id)
+	if err != nil {
+		return fmt.Errorf("storage.IncrementTokenVersion: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// CreateAd creates a new ad together with its images.
 func (s *Storage) CreateAd(ctx context.Context, ad *domain.Ad) (int64, error) {
-	q := `INSERT INTO ads (user_id, author_login, title, text, image_url, price) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+	const q = `INSERT INTO ads (user_id, author_login, title, text, image_url, price, category_id, attributes, latitude, longitude, city, status, expires_at, currency) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, status_changed_at, bumped_at, created_at`
 
-	err := s.pool.QueryRow(ctx, q, ad.UserID, ad.AuthorLogin, ad.Title, ad.Text, ad.ImageURL, ad.Price).Scan(&ad.ID, &ad.CreatedAt)
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, q, ad.UserID, ad.AuthorLogin, ad.Title, ad.Text, ad.ImageURL, ad.Price, ad.CategoryID, attributesOrEmpty(ad.Attributes), ad.Latitude, ad.Longitude, ad.City, ad.Status, ad.ExpiresAt, ad.Currency).Scan(&ad.ID, &ad.StatusChangedAt, &ad.BumpedAt, &ad.CreatedAt); err != nil {
+			return err
+		}
+		return syncAdImages(ctx, tx, ad)
+	})
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			switch pgErr.Code {
 			case pgerrcode.ForeignKeyViolation:
+				if pgErr.ConstraintName == adsCategoryFKey {
+					return 0, storage.ErrCategoryNotFound
+				}
 				return 0, storage.ErrForeignKeyViolation
 			case pgerrcode.UniqueViolation:
 				return 0, storage.ErrAdExists // Assuming a unique constraint on title or something similar
@@ -116,48 +155,289 @@ func (s *Storage) CreateAd(ctx context.Context, ad *domain.Ad) (int64, error) {
 	return ad.ID, nil
 }
 
-// ListAds returns a list of ads with pagination and filtering.
-func (s *Storage) ListAds(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
-	if params == nil {
-		return nil, fmt.Errorf("storage.ListAds: params cannot be nil")
+// GetAdByID finds an ad by its ID.
+func (s *Storage) GetAdByID(ctx context.Context, id int64) (*domain.Ad, error) {
+	const q = `SELECT ` + adColumns + ` FROM ads WHERE id = $1`
+
+	rows, err := s.pool.Query(ctx, q, id)
+	if err != nil {
+		return nil, fmt.Errorf("storage.GetAdByID: %w", err)
+	}
+
+	ad, err := pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.Ad])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrAdNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage.GetAdByID: %w", err)
 	}
 
-	// Build the WHERE clause for price filtering
-	var whereConditions []string
-	var args []interface{}
-	argIndex := 1
+	return &ad, nil
+}
+
+// UpdateAd overwrites the editable fields and the images of an ad owned by ad.UserID.
+// The IDs and variants of ad.Images are refreshed from the database. If the title,
+// text, price or cover change, the previous ones are kept as an edit by editorID;
+// changes of the price or its currency are also recorded in the price history.
+func (s *Storage) UpdateAd(ctx context.Context, ad *domain.Ad, editorID int64) error {
+	const (
+		// The row lock orders concurrent edits, so that each one records the state it replaced
+		versionQ = `
+			INSERT INTO ad_versions (ad_id, title, text, price, currency, image_url, editor_id)
+			SELECT id, title, text, price, currency, image_url, $6 FROM ads
+			WHERE id = $1 AND (title, text, price, currency, image_url) IS DISTINCT FROM ($2, $3, $4, $7, $5)
+			FOR UPDATE`
+		priceQ = `
+			INSERT INTO ad_price_history (ad_id, old_price, old_currency, new_price, new_currency)
+			SELECT id, price, currency, $2, $3 FROM ads WHERE id = $1 AND (price, currency) <> ($2, $3)
+			FOR UPDATE`
+		// On the right-hand side, price and currency are still the old ones.
+		// Prices in different currencies are not compared, so a new currency
+		// starts without a previous price or a drop.
+		q = `
+			UPDATE ads SET title = $1, text = $2, image_url = $3, price = $4, category_id = $5, attributes = $6, latitude = $7, longitude = $8, city = $9, currency = $12,
+				previous_price = CASE WHEN currency <> $12 THEN NULL WHEN price <> $4 THEN price ELSE previous_price END,
+				price_dropped_at = CASE WHEN currency <> $12 THEN NULL WHEN price <> $4 THEN CASE WHEN $4 < price THEN NOW() END ELSE price_dropped_at END
+			WHERE id = $10 AND user_id = $11
+			RETURNING previous_price, price_dropped_at`
+	)
+
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, versionQ, ad.ID, ad.Title, ad.Text, ad.Price, ad.ImageURL, editorID, ad.Currency); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, priceQ, ad.ID, ad.Price, ad.Currency); err != nil {
+			return err
+		}
+		err := tx.QueryRow(ctx, q, ad.Title, ad.Text, ad.ImageURL, ad.Price, ad.CategoryID, attributesOrEmpty(ad.Attributes), ad.Latitude, ad.Longitude, ad.City, ad.ID, ad.UserID, ad.Currency).
+			Scan(&ad.PreviousPrice, &ad.PriceDroppedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.ErrAdNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return syncAdImages(ctx, tx, ad)
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrAdNotFound) {
+			return err
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation && pgErr.ConstraintName == adsCategoryFKey {
+			return storage.ErrCategoryNotFound
+		}
+		return fmt.Errorf("storage.UpdateAd: %w", err)
+	}
+
+	return nil
+}
+
+// syncAdImages makes the stored images of ad match ad.Images, keeping the rows of
+// images that remain, and reads them back.
+func syncAdImages(ctx context.Context, tx pgx.Tx, ad *domain.Ad) error {
+	const (
+		deleteQ = `DELETE FROM ad_images WHERE ad_id = $1 AND url <> ALL($2::text[])`
+		upsertQ = `
+			INSERT INTO ad_images (ad_id, url, position)
+			SELECT $1, u.url, u.ord - 1 FROM unnest($2::text[]) WITH ORDINALITY AS u(url, ord)
+			ON CONFLICT ON CONSTRAINT ad_images_url_key DO UPDATE SET position = EXCLUDED.position`
+		selectQ = `
+			SELECT ai.id, ai.url, ai.position, COALESCE(i.variants, '[]') AS variants
+			FROM ad_images ai LEFT JOIN images i ON i.url = ai.url
+			WHERE ai.ad_id = $1 ORDER BY ai.position`
+	)
+
+	urls := ad.ImageURLs()
+	if _, err := tx.Exec(ctx, deleteQ, ad.ID, urls); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, upsertQ, ad.ID, urls); err != nil {
+		return err
+	}
 
-	if params.MinPrice != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("price >= $%d", argIndex))
-		args = append(args, *params.MinPrice)
-		argIndex++
+	rows, err := tx.Query(ctx, selectQ, ad.ID)
+	if err != nil {
+		return err
+	}
+	images, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.AdImage])
+	if err != nil {
+		return err
+	}
+	ad.Images, ad.ImageVariants = images, nil
+	if len(images) > 0 {
+		ad.ImageVariants = images[0].Variants
 	}
 
-	if params.MaxPrice != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("price <= $%d", argIndex))
-		args = append(args, *params.MaxPrice)
-		argIndex++
+	return nil
+}
+
+// UpdateAdStatus stores ad.Status and ad.ExpiresAt and refreshes ad.StatusChangedAt.
+// It fails with storage.ErrAdConflict if the stored status is no longer from.
+func (s *Storage) UpdateAdStatus(ctx context.Context, ad *domain.Ad, from domain.AdStatus) error {
+	const q = `
+		UPDATE ads SET status = $3, expires_at = $4,
+			status_changed_at = CASE WHEN status = $3 THEN status_changed_at ELSE NOW() END
+		WHERE id = $1 AND status = $2
+		RETURNING status_changed_at`
+
+	err := s.pool.QueryRow(ctx, q, ad.ID, from, ad.Status, ad.ExpiresAt).Scan(&ad.StatusChangedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.ErrAdConflict
+	}
+	if err != nil {
+		return fmt.Errorf("storage.UpdateAdStatus: %w", err)
 	}
 
-	// Build the base query
-	q := "SELECT id, user_id, author_login, title, text, image_url, price, created_at FROM ads"
+	return nil
+}
+
+// ArchiveExpiredAds archives up to limit published and reserved ads whose term has ended
+// and returns their number. Replicas take turns through an advisory lock: if another
+// one holds it, nothing is done and storage.ErrLocked is returned.
+func (s *Storage) ArchiveExpiredAds(ctx context.Context, limit int) (int64, error) {
+	const (
+		lockQ    = `SELECT pg_try_advisory_xact_lock($1)`
+		archiveQ = `
+			UPDATE ads SET status = 'archived', status_changed_at = NOW()
+			WHERE id IN (
+				SELECT id FROM ads
+				WHERE status IN ('published', 'reserved') AND expires_at <= NOW()
+				ORDER BY expires_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)`
+	)
+
+	var archived int64
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var locked bool
+		if err := tx.QueryRow(ctx, lockQ, adsExpiryLockKey).Scan(&locked); err != nil {
+			return err
+		}
+		if !locked {
+			return storage.ErrLocked
+		}
 
-	// Add WHERE clause if there are conditions
-	if len(whereConditions) > 0 {
-		q += " WHERE " + fmt.Sprintf("(%s)", whereConditions[0])
-		for i := 1; i < len(whereConditions); i++ {
-			q += " AND " + whereConditions[i]
+		tag, err := tx.Exec(ctx, archiveQ, limit)
+		if err != nil {
+			return err
 		}
+		archived = tag.RowsAffected()
+		return nil
+	})
+	if errors.Is(err, storage.ErrLocked) {
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("storage.ArchiveExpiredAds: %w", err)
+	}
+
+	return archived, nil
+}
+
+// ListAdVersions returns the previous versions of an ad, the most recent first.
+func (s *Storage) ListAdVersions(ctx context.Context, adID int64) ([]domain.AdVersion, error) {
+	const q = `
+		SELECT v.id, v.ad_id, v.title, v.text, v.price, v.currency, v.image_url, v.editor_id, COALESCE(u.login, '') AS editor_login, v.edited_at
+		FROM ad_versions v LEFT JOIN users u ON u.id = v.editor_id
+		WHERE v.ad_id = $1
+		ORDER BY v.id DESC`
+
+	rows, err := s.pool.Query(ctx, q, adID)
+	if err != nil {
+		return nil, fmt.Errorf("storage.ListAdVersions: %w", err)
 	}
 
-	// Add ORDER BY clause
-	q += fmt.Sprintf(" ORDER BY %s %s", params.SortBy, params.Order)
+	versions, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.AdVersion])
+	if err != nil {
+		return nil, fmt.Errorf("storage.ListAdVersions: %w", err)
+	}
 
-	// Add LIMIT and OFFSET for pagination
-	q += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, params.Limit, params.GetOffset())
+	return versions, nil
+}
 
-	rows, err := s.pool.Query(ctx, q, args...)
+// BumpAd stores ad.BumpedAt, moving the ad up the feed.
+// It fails with storage.ErrAdConflict if the ad was bumped after previous.
+func (s *Storage) BumpAd(ctx context.Context, ad *domain.Ad, previous time.Time) error {
+	const q = `UPDATE ads SET bumped_at = $3 WHERE id = $1 AND bumped_at = $2`
+
+	tag, err := s.pool.Exec(ctx, q, ad.ID, previous, ad.BumpedAt)
+	if err != nil {
+		return fmt.Errorf("storage.BumpAd: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrAdConflict
+	}
+
+	return nil
+}
+
+// DeleteAd deletes an ad by its ID.
+func (s *Storage) DeleteAd(ctx context.Context, id int64) error {
+	const q = `DELETE FROM ads WHERE id = $1`
+
+	tag, err := s.pool.Exec(ctx, q, id)
+	if err != nil {
+		return fmt.Errorf("storage.DeleteAd: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrAdNotFound
+	}
+
+	return nil
+}
+
+// ListAds returns a list of ads with pagination and filtering.
+func (s *Storage) ListAds(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error) {
+	if params == nil {
+		return nil, fmt.Errorf("storage.ListAds: params cannot be nil")
+	}
+
+	b := adsFilter(params)
+
+	// Keyset pagination: continue strictly after the (sort key, id) pair of the cursor.
+	// Ads without a sort key come last, so they follow any cursor with one
+	if params.After != nil {
+		op := ">"
+		if params.Order == "desc" {
+			op = "<"
+		}
+		expr, id := adsSortExpr(params, b), b.arg(params.After.ID)
+		if key := params.After.SortKey(); key != nil {
+			b.where(fmt.Sprintf("((%s, id) %s (%s, %s) OR %s IS NULL)", expr, op, b.arg(key), id, expr))
+		} else {
+			b.where(fmt.Sprintf("%s IS NULL AND id %s %s", expr, op, id))
+		}
+	}
+
+	columns := adColumns
+	if params.Currency != "" {
+		columns += fmt.Sprintf(", %s AS display_price, %s::text AS display_currency", adsPriceExpr(params.Currency, b), b.arg(params.Currency))
+	}
+	if params.Near != nil {
+		columns += ", " + adsDistanceExpr(params.Near, b) + " / 1000 AS distance_km"
+	}
+	q := "SELECT " + columns + " FROM ads" + b.sql()
+
+	// Add ORDER BY clause, with id as a stable tie-breaker
+	nulls := ""
+	if params.SortBy == "distance" || params.SortBy == "price" {
+		// Ads without a location have no distance, and a price without an exchange
+		// rate cannot be converted; either way they always come last
+		nulls = " NULLS LAST"
+	}
+	q += fmt.Sprintf(" ORDER BY %s %s%s, id %s", adsSortExpr(params, b), params.Order, nulls, params.Order)
+
+	// Add LIMIT and OFFSET for pagination. In keyset mode one more ad is fetched,
+	// which only tells that there is a next page
+	limit := params.Limit
+	if params.CursorMode {
+		limit++
+	}
+	q += fmt.Sprintf(" LIMIT %s OFFSET %s", b.arg(limit), b.arg(params.GetOffset()))
+
+	rows, err := s.pool.Query(ctx, q, b.args...)
 	if err != nil {
 		return nil, fmt.Errorf("storage.ListAds: %w", err)
 	}
@@ -169,3 +449,168 @@ func (s *Storage) ListAds(ctx context.Context, params *domain.ListAdsParams) ([]
 
 	return ads, nil
 }
+
+// CountAds returns the number of ads matching the filters of params,
+// ignoring pagination.
+func (s *Storage) CountAds(ctx context.Context, params *domain.ListAdsParams) (int64, error) {
+	if params == nil {
+		return 0, fmt.Errorf("storage.CountAds: params cannot be nil")
+	}
+
+	b := adsFilter(params)
+	q := "SELECT COUNT(*) FROM ads" + b.sql()
+
+	var total int64
+	if err := s.pool.QueryRow(ctx, q, b.args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("storage.CountAds: %w", err)
+	}
+
+	return total, nil
+}
+
+// SuggestTitles returns up to limit distinct ad titles for autocomplete.
+// Titles starting with prefix come first, followed by fuzzy trigram matches.
+func (s *Storage) SuggestTitles(ctx context.Context, prefix string, limit int) ([]string, error) {
+	const q = `
+		SELECT title FROM ads
+		WHERE status = 'published' AND expires_at > NOW() AND (title ILIKE $1 OR $2 <% title)
+		GROUP BY title
+		ORDER BY bool_or(title ILIKE $1) DESC, max(word_similarity($2, title)) DESC, title
+		LIMIT $3`
+
+	rows, err := s.pool.Query(ctx, q, likePrefix(prefix), prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("storage.SuggestTitles: %w", err)
+	}
+
+	titles, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("storage.SuggestTitles: %w", err)
+	}
+
+	return titles, nil
+}
+
+// ListCategories returns all categories as a flat list ordered by name.
+func (s *Storage) ListCategories(ctx context.Context) ([]domain.Category, error) {
+	const q = `SELECT id, parent_id, name, slug, attribute_schema FROM categories ORDER BY name`
+
+	rows, err := s.pool.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("storage.ListCategories: %w", err)
+	}
+
+	categories, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.Category])
+	if err != nil {
+		return nil, fmt.Errorf("storage.ListCategories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// GetCategoryByID finds a category by its ID.
+func (s *Storage) GetCategoryByID(ctx context.Context, id int64) (*domain.Category, error) {
+	const q = `SELECT id, parent_id, name, slug, attribute_schema FROM categories WHERE id = $1`
+
+	rows, err := s.pool.Query(ctx, q, id)
+	if err != nil {
+		return nil, fmt.Errorf("storage.GetCategoryByID: %w", err)
+	}
+
+	c, err := pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.Category])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage.GetCategoryByID: %w", err)
+	}
+
+	return &c, nil
+}
+
+// attributesOrEmpty keeps the NOT NULL attributes column as an empty object for ads without attributes.
+func attributesOrEmpty(attrs map[string]any) map[string]any {
+	if attrs == nil {
+		return map[string]any{}
+	}
+	return attrs
+}
+
+// CountAdsByCategory returns the number of matching ads per category, most populated first.
+// Like the category filter of the feed, a category counts the ads of its descendants too.
+func (s *Storage) CountAdsByCategory(ctx context.Context, params *domain.ListAdsParams) ([]domain.FacetCount, error) {
+	b := adsFilter(params)
+	q := `WITH RECURSIVE subtree AS (
+			SELECT id AS root_id, id FROM categories
+			UNION ALL
+			SELECT subtree.root_id, c.id FROM categories c JOIN subtree ON c.parent_id = subtree.id
+		)
+		SELECT c.id, c.name AS label, SUM(f.count)::bigint AS count
+		FROM (SELECT category_id, COUNT(*) AS count FROM ads` + b.sql() + ` GROUP BY category_id) f
+		JOIN subtree ON subtree.id = f.category_id
+		JOIN categories c ON c.id = subtree.root_id
+		GROUP BY c.id, c.name
+		ORDER BY count DESC, c.name`
+
+	rows, err := s.pool.Query(ctx, q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage.CountAdsByCategory: %w", err)
+	}
+
+	counts, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.FacetCount])
+	if err != nil {
+		return nil, fmt.Errorf("storage.CountAdsByCategory: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CountAdsByPriceBucket returns the number of matching ads per price bucket.
+// The result maps a 1-based bucket index over the ascending bounds to its count;
+// index 0 holds ads below the first bound.
+func (s *Storage) CountAdsByPriceBucket(ctx context.Context, params *domain.ListAdsParams, bounds []int64) (map[int]int64, error) {
+	b := adsFilter(params)
+	price := adsPriceExpr(params.Currency, b)
+	// Prices that cannot be converted fall into no bucket
+	b.where(price + " IS NOT NULL")
+	q := fmt.Sprintf("SELECT width_bucket(%s, %s::bigint[]) AS bucket, COUNT(*) FROM ads%s GROUP BY bucket", price, b.arg(bounds), b.sql())
+
+	rows, err := s.pool.Query(ctx, q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage.CountAdsByPriceBucket: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int64)
+	for rows.Next() {
+		var bucket int
+		var count int64
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, fmt.Errorf("storage.CountAdsByPriceBucket: %w", err)
+		}
+		counts[bucket] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("storage.CountAdsByPriceBucket: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CountAdsByAuthor returns the number of matching ads for the top limit authors.
+func (s *Storage) CountAdsByAuthor(ctx context.Context, params *domain.ListAdsParams, limit int) ([]domain.FacetCount, error) {
+	b := adsFilter(params)
+	q := fmt.Sprintf("SELECT author_login AS label, COUNT(*) AS count FROM ads%s GROUP BY author_login ORDER BY count DESC, author_login LIMIT %s", b.sql(), b.arg(limit))
+
+	rows, err := s.pool.Query(ctx, q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage.CountAdsByAuthor: %w", err)
+	}
+
+	counts, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.FacetCount])
+	if err != nil {
+		return nil, fmt.Errorf("storage.CountAdsByAuthor: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+)
+
+// searchConfig is the text search configuration used by the search_vector column.
+const searchConfig = "russian"
+
+// whereBuilder accumulates WHERE conditions together with their positional arguments.
+type whereBuilder struct {
+	conds []string
+	args  []any
+}
+
+// arg registers a query argument and returns its placeholder.
+func (b *whereBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// where adds a condition; conditions are joined with AND.
+func (b *whereBuilder) where(cond string) {
+	b.conds = append(b.conds, cond)
+}
+
+// sql returns the WHERE clause, or an empty string if there are no conditions.
+func (b *whereBuilder) sql() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conds, " AND ")
+}
+
+// adsFilter builds the conditions shared by every query over the ad feed,
+// so that listings and counts always agree.
+func adsFilter(params *domain.ListAdsParams) *whereBuilder {
+	b := &whereBuilder{}
+
+	statuses := params.VisibleStatuses()
+	names := make([]string, len(statuses))
+	for i, st := range statuses {
+		names[i] = string(st)
+	}
+	b.where("status = ANY(" + b.arg(names) + "::text[])")
+	if params.OwnerID != nil {
+		b.where("user_id = " + b.arg(*params.OwnerID))
+	} else {
+		// Expired ads are hidden before the scheduler gets to archive them
+		b.where("expires_at > NOW()")
+	}
+	if params.MinPrice != nil {
+		b.where(adsPriceExpr(params.Currency, b) + " >= " + b.arg(*params.MinPrice))
+	}
+	if params.MaxPrice != nil {
+		b.where(adsPriceExpr(params.Currency, b) + " <= " + b.arg(*params.MaxPrice))
+	}
+	if params.PriceDropped {
+		b.where("price_dropped_at IS NOT NULL")
+	}
+	if params.CategoryID != nil {
+		// Include ads from every descendant of the category
+		b.where(fmt.Sprintf(`category_id IN (
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM categories WHERE id = %s
+				UNION ALL
+				SELECT c.id FROM categories c JOIN subtree ON c.parent_id = subtree.id
+			)
+			SELECT id FROM subtree)`, b.arg(*params.CategoryID)))
+	}
+	for _, f := range params.Attributes {
+		switch f.Op {
+		case "eq":
+			// Containment is type-exact and served by the GIN index on attributes
+			b.where("attributes @> " + b.arg(map[string]any{f.Key: f.Value}) + "::jsonb")
+		case "min", "max":
+			op := ">="
+			if f.Op == "max" {
+				op = "<="
+			}
+			// CASE guards the cast against ads where the attribute is not a number
+			key := b.arg(f.Key)
+			b.where(fmt.Sprintf("CASE WHEN jsonb_typeof(attributes -> %s) = 'number' THEN (attributes ->> %s)::numeric END %s %s",
+				key, key, op, b.arg(f.Value)))
+		}
+	}
+	if params.Near != nil && params.RadiusKm != nil {
+		// earth_box is an index-backed bounding cube; earth_distance trims its corners
+		origin := fmt.Sprintf("ll_to_earth(%s, %s)", b.arg(params.Near.Lat), b.arg(params.Near.Lon))
+		radius := b.arg(*params.RadiusKm * 1000)
+		b.where(fmt.Sprintf("earth_box(%s, %s) @> ll_to_earth(latitude, longitude) AND earth_distance(%s, ll_to_earth(latitude, longitude)) <= %s",
+			origin, radius, origin, radius))
+	}
+	if params.Query != "" {
+		// Full-text match, or a fuzzy trigram match on the title to tolerate typos
+		q := b.arg(params.Query)
+		b.where(fmt.Sprintf("(search_vector @@ websearch_to_tsquery('%s', %s) OR %s <%% title)", searchConfig, q, q))
+	}
+
+	return b
+}
+
+// adsSortExpr returns the SQL expression the ad feed is ordered by.
+func adsSortExpr(params *domain.ListAdsParams, b *whereBuilder) string {
+	switch params.SortBy {
+	case "relevance":
+		q := b.arg(params.Query)
+		return fmt.Sprintf("GREATEST(ts_rank(search_vector, websearch_to_tsquery('%s', %s)), word_similarity(%s, title))", searchConfig, q, q)
+	case "distance":
+		return adsDistanceExpr(params.Near, b)
+	case "price":
+		return adsPriceExpr(params.Currency, b)
+	}
+	return adsSortColumn(params.SortBy)
+}
+
+// adsSortColumn returns the column behind a sort_by value. Sorting by date
+// uses the bump time, so bumped ads come back to the top of the feed.
+func adsSortColumn(sortBy string) string {
+	if sortBy == "created_at" {
+		return "bumped_at"
+	}
+	return sortBy
+}
+
+// adsPriceExpr returns the SQL expression for the ad price in currency, rounded to
+// minor units, or the price as is if currency is empty. Ads already in currency
+// keep their raw price, so only ads in other currencies look up the rates. It is
+// NULL when a rate is missing; the services only accept currencies with a rate,
+// so that is left to ads saved before their currency had one.
+func adsPriceExpr(currency domain.Currency, b *whereBuilder) string {
+	if currency == "" {
+		return "price"
+	}
+	c := b.arg(currency)
+	return fmt.Sprintf(`CASE WHEN currency = %s THEN price
+		ELSE ROUND(price * (SELECT rate FROM exchange_rates WHERE currency = ads.currency)
+			/ (SELECT rate FROM exchange_rates WHERE currency = %s))::bigint END`, c, c)
+}
+
+// adsDistanceExpr returns the SQL expression for the great-circle distance in metres
+// from p to the ad location; it is NULL for ads without a location.
+func adsDistanceExpr(p *domain.GeoPoint, b *whereBuilder) string {
+	return fmt.Sprintf("earth_distance(ll_to_earth(%s, %s), ll_to_earth(latitude, longitude))", b.arg(p.Lat), b.arg(p.Lon))
+}
+
+// likePrefix escapes LIKE wildcards in s and turns it into a prefix pattern.
+func likePrefix(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s) + "%"
+}
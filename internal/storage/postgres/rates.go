@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListExchangeRates returns the exchange rates of every currency that has one.
+func (s *Storage) ListExchangeRates(ctx context.Context) ([]domain.ExchangeRate, error) {
+	const q = `SELECT currency, rate, updated_at FROM exchange_rates ORDER BY currency`
+
+	rows, err := s.pool.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("storage.ListExchangeRates: %w", err)
+	}
+
+	rates, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.ExchangeRate])
+	if err != nil {
+		return nil, fmt.Errorf("storage.ListExchangeRates: %w", err)
+	}
+
+	return rates, nil
+}
+
+// SetExchangeRates creates or replaces the given exchange rates in one statement.
+func (s *Storage) SetExchangeRates(ctx context.Context, rates []domain.ExchangeRate) error {
+	const q = `
+		INSERT INTO exchange_rates (currency, rate, updated_at)
+		SELECT r.currency, r.rate, NOW() FROM unnest($1::text[], $2::numeric[]) AS r(currency, rate)
+		ON CONFLICT (currency) DO UPDATE SET rate = EXCLUDED.rate, updated_at = EXCLUDED.updated_at`
+
+	currencies := make([]string, len(rates))
+	values := make([]float64, len(rates))
+	for i, r := range rates {
+		currencies[i] = string(r.Currency)
+		values[i] = r.Rate
+	}
+
+	if _, err := s.pool.Exec(ctx, q, currencies, values); err != nil {
+		return fmt.Errorf("storage.SetExchangeRates: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// sessionTouchInterval is how stale last_seen_at may get before a request
+// updates it, so that not every request writes to the sessions table.
+const sessionTouchInterval = "1 minute"
+
+// CreateSession stores a new session together with its first refresh token,
+// whose family is set to the session ID.
+func (s *Storage) CreateSession(ctx context.Context, session *domain.Session, t *domain.RefreshToken) error {
+	const q = `
+		INSERT INTO sessions (user_id, user_agent, ip) VALUES ($1, $2, $3)
+		RETURNING id::text, created_at, last_seen_at`
+
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, q, session.UserID, session.UserAgent, session.IP).Scan(&session.ID, &session.CreatedAt, &session.LastSeenAt)
+		if err != nil {
+			return err
+		}
+		t.FamilyID = session.ID
+		return insertRefreshToken(ctx, tx, t)
+	})
+	if err != nil {
+		return fmt.Errorf("storage.CreateSession: %w", err)
+	}
+
+	return nil
+}
+
+// TouchSession records that a session is in use. It returns storage.ErrSessionNotFound
+// if the session does not exist or has been revoked.
+func (s *Storage) TouchSession(ctx context.Context, id string) error {
+	const q = `
+		WITH touched AS (
+			UPDATE sessions SET last_seen_at = NOW()
+			WHERE id = $1::uuid AND revoked_at IS NULL AND last_seen_at < NOW() - INTERVAL '` + sessionTouchInterval + `'
+		)
+		SELECT revoked_at IS NULL FROM sessions WHERE id = $1::uuid`
+
+	var active bool
+	err := s.pool.QueryRow(ctx, q, id).Scan(&active)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidUUID(err) || (err == nil && !active) {
+		return storage.ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("storage.TouchSession: %w", err)
+	}
+
+	return nil
+}
+
+// ListSessions returns the active sessions of a user, most recently used first.
+// A session is active until it is revoked or its refresh token expires.
+func (s *Storage) ListSessions(ctx context.Context, userID int64) ([]domain.Session, error) {
+	const q = `
+		SELECT id::text AS id, user_id, user_agent, ip, created_at, last_seen_at, revoked_at
+		FROM sessions s
+		WHERE user_id = $1 AND revoked_at IS NULL
+			AND EXISTS (
+				SELECT 1 FROM refresh_tokens t
+				WHERE t.family_id = s.id AND t.used_at IS NULL AND t.revoked_at IS NULL AND t.expires_at > NOW()
+			)
+		ORDER BY last_seen_at DESC, created_at DESC`
+
+	rows, err := s.pool.Query(ctx, q, userID)
+	if err != nil {
+		return nil, fmt.Errorf("storage.ListSessions: %w", err)
+	}
+
+	sessions, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.Session])
+	if err != nil {
+		return nil, fmt.Errorf("storage.ListSessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes a session of a user together with its refresh tokens.
+// It returns storage.ErrSessionNotFound if the user has no such session or it is
+// revoked already.
+func (s *Storage) RevokeSession(ctx context.Context, userID int64, id string) error {
+	const (
+		sessionQ = `UPDATE sessions SET revoked_at = NOW() WHERE id = $1::uuid AND user_id = $2 AND revoked_at IS NULL`
+		tokensQ  = `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1::uuid AND revoked_at IS NULL`
+	)
+
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, sessionQ, id, userID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return storage.ErrSessionNotFound
+		}
+		_, err = tx.Exec(ctx, tokensQ, id)
+		return err
+	})
+	if errors.Is(err, storage.ErrSessionNotFound) || isInvalidUUID(err) {
+		return storage.ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("storage.RevokeSession: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUserSessions revokes every session of a user together with its refresh tokens.
+func (s *Storage) RevokeUserSessions(ctx context.Context, userID int64) error {
+	const (
+		sessionsQ = `UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+		tokensQ   = `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	)
+
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, sessionsQ, userID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, tokensQ, userID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("storage.RevokeUserSessions: %w", err)
+	}
+
+	return nil
+}
+
+// isInvalidUUID reports whether err is Postgres rejecting a malformed UUID,
+// e.g. a session ID taken from a URL.
+func isInvalidUUID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.InvalidTextRepresentation
+}
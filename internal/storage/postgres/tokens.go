@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/felix-kado/vk-test-task/internal/domain"
+	"github.com/felix-kado/vk-test-task/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// insertRefreshToken inserts t into its family and fills in its ID and creation time.
+func insertRefreshToken(ctx context.Context, tx pgx.Tx, t *domain.RefreshToken) error {
+	const q = `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2::uuid, $3, $4)
+		RETURNING id, created_at`
+
+	return tx.QueryRow(ctx, q, t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt).Scan(&t.ID, &t.CreatedAt)
+}
+
+// FindRefreshToken finds a refresh token by the hash of its value, whether it is
+// still usable or not.
+func (s *Storage) FindRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	const q = `
+		SELECT id, user_id, family_id::text AS family_id, token_hash, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens WHERE token_hash = $1`
+
+	rows, err := s.pool.Query(ctx, q, tokenHash)
+	if err != nil {
+		return nil, fmt.Errorf("storage.FindRefreshToken: %w", err)
+	}
+
+	t, err := pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.RefreshToken])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage.FindRefreshToken: %w", err)
+	}
+
+	return &t, nil
+}
+
+// RotateRefreshToken marks the token usedID as used and stores next in one transaction.
+// It returns storage.ErrRefreshTokenUsed if the token was used or revoked meanwhile,
+// so that of two concurrent refreshes with the same token only one succeeds.
+func (s *Storage) RotateRefreshToken(ctx context.Context, usedID int64, next *domain.RefreshToken) error {
+	const q = `UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`
+
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, q, usedID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return storage.ErrRefreshTokenUsed
+		}
+		return insertRefreshToken(ctx, tx, next)
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrRefreshTokenUsed) {
+			return err
+		}
+		return fmt.Errorf("storage.RotateRefreshToken: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeToken records an access token as revoked until it expires. Records of
+// tokens that have expired by now are removed on the way.
+func (s *Storage) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	const q = `
+		WITH pruned AS (DELETE FROM revoked_tokens WHERE expires_at <= NOW())
+		INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING`
+
+	if _, err := s.pool.Exec(ctx, q, jti, expiresAt); err != nil {
+		return fmt.Errorf("storage.RevokeToken: %w", err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked reports whether an access token has been revoked.
+func (s *Storage) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	const q = `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1 AND expires_at > NOW())`
+
+	var revoked bool
+	if err := s.pool.QueryRow(ctx, q, jti).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("storage.IsTokenRevoked: %w", err)
+	}
+
+	return revoked, nil
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/felix-kado/vk-test-task/internal/domain"
 )
@@ -10,9 +11,66 @@ type UserRepository interface {
 	CreateUser(ctx context.Context, u *domain.User) error
 	FindByLogin(ctx context.Context, login string) (*domain.User, error)
 	FindUserByID(ctx context.Context, id int64) (*domain.User, error)
+	IncrementTokenVersion(ctx context.Context, id int64) error
+}
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *domain.Session, t *domain.RefreshToken) error
+	TouchSession(ctx context.Context, id string) error
+	ListSessions(ctx context.Context, userID int64) ([]domain.Session, error)
+	RevokeSession(ctx context.Context, userID int64, id string) error
+	RevokeUserSessions(ctx context.Context, userID int64) error
+	FindRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, usedID int64, next *domain.RefreshToken) error
+}
+
+// RevocationStore keeps the IDs of revoked access tokens until the tokens expire.
+type RevocationStore interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// AttemptStore counts consecutive failed attempts per key. Failures made before
+// since are forgotten, and the count starts over. An attempt is recorded as failed
+// up front and returns the failures before it; concurrent attempts with one key are
+// counted one after another, so each sees all earlier ones. RevertFailure undoes a
+// recorded attempt that did not fail, given the failures RecordFailure returned for it.
+type AttemptStore interface {
+	RecordFailure(ctx context.Context, key string, at, since time.Time) (domain.Attempts, error)
+	RevertFailure(ctx context.Context, key string, prev domain.Attempts) error
+	ResetFailures(ctx context.Context, key string) error
 }
 
 type AdRepository interface {
 	CreateAd(ctx context.Context, ad *domain.Ad) (int64, error)
+	GetAdByID(ctx context.Context, id int64) (*domain.Ad, error)
+	UpdateAd(ctx context.Context, ad *domain.Ad, editorID int64) error
+	ListAdVersions(ctx context.Context, adID int64) ([]domain.AdVersion, error)
+	UpdateAdStatus(ctx context.Context, ad *domain.Ad, from domain.AdStatus) error
+	ArchiveExpiredAds(ctx context.Context, limit int) (int64, error)
+	BumpAd(ctx context.Context, ad *domain.Ad, previous time.Time) error
+	DeleteAd(ctx context.Context, id int64) error
 	ListAds(ctx context.Context, params *domain.ListAdsParams) ([]domain.Ad, error)
+	CountAds(ctx context.Context, params *domain.ListAdsParams) (int64, error)
+	SuggestTitles(ctx context.Context, prefix string, limit int) ([]string, error)
+	CountAdsByCategory(ctx context.Context, params *domain.ListAdsParams) ([]domain.FacetCount, error)
+	CountAdsByPriceBucket(ctx context.Context, params *domain.ListAdsParams, bounds []int64) (map[int]int64, error)
+	CountAdsByAuthor(ctx context.Context, params *domain.ListAdsParams, limit int) ([]domain.FacetCount, error)
+}
+
+type CategoryRepository interface {
+	ListCategories(ctx context.Context) ([]domain.Category, error)
+	GetCategoryByID(ctx context.Context, id int64) (*domain.Category, error)
+}
+
+type ExchangeRateRepository interface {
+	ListExchangeRates(ctx context.Context) ([]domain.ExchangeRate, error)
+	SetExchangeRates(ctx context.Context, rates []domain.ExchangeRate) error
+}
+
+type ImageRepository interface {
+	CreateImage(ctx context.Context, img *domain.UploadedImage) error
+	ClaimPendingImages(ctx context.Context, limit int) ([]domain.UploadedImage, error)
+	SetImageVariants(ctx context.Context, id int64, variants []domain.ImageVariant) error
+	FailImage(ctx context.Context, id int64, maxAttempts int) error
 }